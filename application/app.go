@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/ScrpTrx-Go/GoTGParse/internal/domain/contracts"
+	"github.com/ScrpTrx-Go/GoTGParse/internal/domain/model"
 	pkg "github.com/ScrpTrx-Go/GoTGParse/pkg/logger"
 )
 
@@ -14,15 +15,17 @@ type App struct {
 	Logger   pkg.Logger
 	Db       contracts.SaverPostgres
 	Reporter contracts.Reporter
+	FollowUp contracts.FollowUpLinker
 }
 
-func NewApp(fetcher contracts.PostFetcher, analyzer contracts.PostAnalyzer, logger pkg.Logger, db contracts.SaverPostgres, reporter contracts.Reporter) *App {
+func NewApp(fetcher contracts.PostFetcher, analyzer contracts.PostAnalyzer, logger pkg.Logger, db contracts.SaverPostgres, reporter contracts.Reporter, followUp contracts.FollowUpLinker) *App {
 	return &App{
 		Fetcher:  fetcher,
 		Analyzer: analyzer,
 		Logger:   logger,
 		Db:       db,
 		Reporter: reporter,
+		FollowUp: followUp,
 	}
 }
 
@@ -50,6 +53,14 @@ func (a *App) Run(ctx context.Context, from, to time.Time) {
 		a.Logger.Info("Loading newer posts", "from", newFrom, "to", to)
 		a.fetchAndSave(ctx, newFrom, to)
 	}
+
+	linked, err := a.FollowUp.Link(ctx, from, to)
+	if err != nil {
+		a.Logger.Error("Failed to link follow-ups", "err", err)
+	} else {
+		a.Logger.Info("Linked follow-ups to errands", "count", linked)
+	}
+
 	if err := a.Reporter.GenerateFullReport(ctx, from, to); err != nil {
 		a.Logger.Error("Failed to Generate report", "err", err)
 		return
@@ -57,10 +68,66 @@ func (a *App) Run(ctx context.Context, from, to time.Time) {
 }
 
 func (a *App) fetchAndSave(ctx context.Context, from, to time.Time) {
-	outFromFetch := a.Fetcher.RunFetchPipelene(ctx, from, to)
-	outFromAnalyze := a.Analyzer.RunAnalyzePipeline(ctx, outFromFetch)
+	outFromFetch, stats := a.Fetcher.RunFetchPipelene(ctx, from, to)
+	outFromAnalyze, analysis := a.Analyzer.RunAnalyzePipeline(ctx, outFromFetch)
 
 	if err := a.Db.SaveBatch(ctx, outFromAnalyze); err != nil {
 		a.Logger.Error("Failed to save posts", "err", err)
 	}
+
+	a.logFetchSummary(stats)
+	a.logAnalysisSummary(analysis)
+	if err := a.Db.SaveFetchStats(ctx, stats); err != nil {
+		a.Logger.Error("Failed to save fetch stats", "err", err)
+	}
+}
+
+func (a *App) logFetchSummary(stats *model.FetchStats) {
+	for _, ch := range stats.Channels() {
+		a.Logger.Info("Fetch summary",
+			"username", ch.Username,
+			"pages_read", ch.PagesRead,
+			"local_pages_read", ch.LocalPagesRead,
+			"messages_seen", ch.MessagesSeen,
+			"fetched", ch.Fetched,
+			"filtered", ch.Filtered,
+			"link_failures", ch.LinkFailures,
+			"errors", ch.Errors,
+			"duration", ch.Duration.String(),
+		)
+	}
+	totals := stats.Totals()
+	a.Logger.Info("Fetch run completed",
+		"pages_read", totals.PagesRead,
+		"local_pages_read", totals.LocalPagesRead,
+		"messages_seen", totals.MessagesSeen,
+		"fetched", totals.Fetched,
+		"filtered", totals.FilteredTotal(),
+		"link_failures", totals.LinkFailures,
+		"errors", totals.Errors,
+		"duration", totals.Duration.String(),
+	)
+}
+
+func (a *App) logAnalysisSummary(stats *model.AnalysisStats) {
+	for _, ch := range stats.Channels() {
+		a.Logger.Info("Analysis summary",
+			"username", ch.Username,
+			"errands", ch.Errands,
+			"skipped", ch.Skipped,
+		)
+	}
+	workers := stats.Workers()
+	busiest := 0
+	for _, w := range workers {
+		busiest = max(busiest, w.Errands+w.Skipped)
+	}
+	totals := stats.Totals()
+	a.Logger.Info("Analysis run completed",
+		"errands", totals.Errands,
+		"skipped", totals.Skipped,
+		"workers", len(workers),
+		"busiest_worker_posts", busiest,
+		"duration", stats.Duration().String(),
+	)
 }
@@ -0,0 +1,212 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/ScrpTrx-Go/GoTGParse/internal/domain/contracts"
+	"github.com/ScrpTrx-Go/GoTGParse/internal/domain/model"
+	pkg "github.com/ScrpTrx-Go/GoTGParse/pkg/logger"
+)
+
+// Reanalyzer classifies stored posts again with the current dictionaries and
+// rules, without fetching them from Telegram.
+type Reanalyzer struct {
+	Analyzer contracts.PostAnalyzer
+	Store    contracts.AnalysisStore
+	Logger   pkg.Logger
+}
+
+func NewReanalyzer(analyzer contracts.PostAnalyzer, store contracts.AnalysisStore, logger pkg.Logger) *Reanalyzer {
+	return &Reanalyzer{
+		Analyzer: analyzer,
+		Store:    store,
+		Logger:   logger,
+	}
+}
+
+// ReanalyzeSummary counts what changed. A post can count in several of the
+// change counters.
+type ReanalyzeSummary struct {
+	Total             int
+	Changed           int
+	BecameErrand      int
+	NoLongerErrand    int
+	RegionsChanged    int
+	TypeChanged       int
+	ErrorChanged      int
+	CategoriesChanged int
+	// Versions counts the posts by the dictionary version they were analyzed
+	// with before.
+	Versions map[string]int
+	Version  string
+}
+
+type analysis struct {
+	errand     bool
+	regions    []string
+	errandType bool
+	errorType  string
+	categories []string
+	version    string
+}
+
+func analysisOf(post *model.Post) analysis {
+	return analysis{
+		errand:     post.IsErrand,
+		regions:    post.Regions,
+		errandType: post.ErrandType,
+		errorType:  post.ErrorType,
+		categories: post.Categories,
+		version:    post.DictionaryVersion,
+	}
+}
+
+// updateBatch is how many reanalyzed posts are written back at once.
+const updateBatch = 1000
+
+// Reanalyze streams the posts of the period through the analyzer and writes
+// the new results back in batches as the posts leave the pipeline. An empty
+// username selects all channels.
+func (r *Reanalyzer) Reanalyze(ctx context.Context, from, to time.Time, username string) (*ReanalyzeSummary, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stored := make(chan *model.Post)
+	streamErr := make(chan error, 1)
+	go func() {
+		streamErr <- r.Store.StreamPosts(ctx, from, to, username, stored)
+	}()
+
+	// The workers update the posts in place, so the results before the
+	// analysis are kept until a post comes out of the pipeline.
+	var mu sync.Mutex
+	before := make(map[*model.Post]analysis)
+	in := make(chan *model.Post)
+	go func() {
+		defer close(in)
+		for post := range stored {
+			mu.Lock()
+			before[post] = analysisOf(post)
+			mu.Unlock()
+			resetAnalysis(post)
+			select {
+			case <-ctx.Done():
+				for range stored {
+				}
+				return
+			case in <- post:
+			}
+		}
+	}()
+
+	summary := &ReanalyzeSummary{Versions: make(map[string]int)}
+	batch := make([]*model.Post, 0, updateBatch)
+	var updateErr error
+	update := func() {
+		if updateErr == nil && len(batch) > 0 {
+			if updateErr = r.Store.UpdateAnalysis(ctx, batch); updateErr != nil {
+				cancel()
+			}
+		}
+		batch = make([]*model.Post, 0, updateBatch)
+	}
+	out, _ := r.Analyzer.RunAnalyzePipeline(ctx, in)
+	for post := range out {
+		mu.Lock()
+		prev := before[post]
+		delete(before, post)
+		mu.Unlock()
+		summary.add(prev, analysisOf(post))
+		summary.Version = post.DictionaryVersion
+		batch = append(batch, post)
+		if len(batch) == updateBatch {
+			update()
+		}
+	}
+	streamed := <-streamErr
+	if updateErr != nil {
+		return nil, updateErr
+	}
+	if streamed != nil {
+		return nil, streamed
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	update()
+	if updateErr != nil {
+		return nil, updateErr
+	}
+	return summary, nil
+}
+
+// resetAnalysis clears the analyzer results of a stored post.
+func resetAnalysis(post *model.Post) {
+	post.IsErrand = false
+	post.Regions = nil
+	post.SecondaryRegions = nil
+	post.Localities = nil
+	post.ErrandType = false
+	post.ErrorType = ""
+	post.MatchedRule = ""
+	post.Trace = nil
+	post.ErrandConfidence = 0
+	post.TypeConfidence = 0
+	post.RegionConfidence = nil
+	post.Details = nil
+	post.Categories = nil
+	post.Fingerprint = 0
+}
+
+func (s *ReanalyzeSummary) add(before, after analysis) {
+	s.Total++
+	s.Versions[before.version]++
+	changed := false
+	if before.errand != after.errand {
+		changed = true
+		if after.errand {
+			s.BecameErrand++
+		} else {
+			s.NoLongerErrand++
+		}
+	}
+	if !slices.Equal(before.regions, after.regions) {
+		changed = true
+		s.RegionsChanged++
+	}
+	if before.errandType != after.errandType {
+		changed = true
+		s.TypeChanged++
+	}
+	if before.errorType != after.errorType {
+		changed = true
+		s.ErrorChanged++
+	}
+	if !slices.Equal(before.categories, after.categories) {
+		changed = true
+		s.CategoriesChanged++
+	}
+	if changed {
+		s.Changed++
+	}
+}
+
+func (s *ReanalyzeSummary) WriteText(w io.Writer) {
+	fmt.Fprintf(w, "Posts reanalyzed: %d with dictionaries %s\n", s.Total, s.Version)
+	for _, version := range slices.Sorted(maps.Keys(s.Versions)) {
+		fmt.Fprintf(w, "  previously analyzed with %q: %d\n", version, s.Versions[version])
+	}
+	fmt.Fprintf(w, "Changed: %d\n", s.Changed)
+	fmt.Fprintf(w, "  became errands:     %d\n", s.BecameErrand)
+	fmt.Fprintf(w, "  no longer errands:  %d\n", s.NoLongerErrand)
+	fmt.Fprintf(w, "  regions changed:    %d\n", s.RegionsChanged)
+	fmt.Fprintf(w, "  type changed:       %d\n", s.TypeChanged)
+	fmt.Fprintf(w, "  error changed:      %d\n", s.ErrorChanged)
+	fmt.Fprintf(w, "  categories changed: %d\n", s.CategoriesChanged)
+}
@@ -0,0 +1,126 @@
+package application_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ScrpTrx-Go/GoTGParse/application"
+	"github.com/ScrpTrx-Go/GoTGParse/internal/config"
+	"github.com/ScrpTrx-Go/GoTGParse/internal/domain/model"
+	"github.com/ScrpTrx-Go/GoTGParse/internal/service/analyzer"
+	pkg "github.com/ScrpTrx-Go/GoTGParse/pkg/logger"
+)
+
+type memoryStore struct {
+	posts   []*model.Post
+	updated []*model.Post
+	batches int
+	err     error
+}
+
+func (m *memoryStore) StreamPosts(_ context.Context, _, _ time.Time, _ string, out chan<- *model.Post) error {
+	defer close(out)
+	for _, post := range m.posts {
+		out <- post
+	}
+	return nil
+}
+
+func (m *memoryStore) UpdateAnalysis(_ context.Context, posts []*model.Post) error {
+	m.batches++
+	m.updated = append(m.updated, posts...)
+	return m.err
+}
+
+func newReanalyzer(t *testing.T, store *memoryStore) *application.Reanalyzer {
+	t.Helper()
+	log, err := pkg.NewZapLogger(config.LoggerConfig{Level: "error", FilePath: filepath.Join(t.TempDir(), "app.log")})
+	if err != nil {
+		t.Fatalf("NewZapLogger: %v", err)
+	}
+	rules, err := analyzer.NewChannelRules([]config.ChannelRule{{
+		Username:      "sledcom_press",
+		Detector:      analyzer.DetectorTitle,
+		Dictionaries:  []string{analyzer.DictPrefix, analyzer.DictVerbs, analyzer.DictPSK},
+		ReportSection: config.SectionSledcom,
+	}})
+	if err != nil {
+		t.Fatalf("NewChannelRules: %v", err)
+	}
+	workers := analyzer.NewAnalyzeWorkers(2, log, analyzer.NewDictionariesCreator().CreateDictionaries(), rules, nil, nil, 0.5)
+	return application.NewReanalyzer(analyzer.NewPostPipeline(log, workers, config.AnalyzerOutput{}), store, log)
+}
+
+func TestReanalyze(t *testing.T) {
+	store := &memoryStore{posts: []*model.Post{
+		{ID: 1, Username: "sledcom_press", IsErrand: true, Text: "Совещание\nТекст", ErrandConfidence: 1, Regions: []string{"Омская область"}, DictionaryVersion: "old"},
+		{ID: 2, Username: "sledcom_press", IsErrand: true, Text: "❗️Председатель поручил доложить\nРуководителю СУ по Омской области поручено доложить", ErrandConfidence: 1, Regions: []string{"Омская область"}, DictionaryVersion: "old"},
+	}}
+	summary, err := newReanalyzer(t, store).Reanalyze(context.Background(), time.Time{}, time.Time{}, "")
+	if err != nil {
+		t.Fatalf("Reanalyze: %v", err)
+	}
+	if len(store.updated) != 2 || summary.Total != 2 || summary.NoLongerErrand != 1 || summary.Changed != 1 || summary.Versions["old"] != 2 {
+		t.Errorf("summary = %+v, updated %d", summary, len(store.updated))
+	}
+	if store.posts[0].IsErrand || store.posts[0].DictionaryVersion != analyzer.EmbeddedDictionariesVersion {
+		t.Errorf("dropped post = %+v", store.posts[0])
+	}
+}
+
+func TestReanalyzeBatches(t *testing.T) {
+	store := &memoryStore{}
+	for i := range 2500 {
+		store.posts = append(store.posts, &model.Post{ID: int64(i), Username: "sledcom_press", Text: "Совещание\nТекст", DictionaryVersion: "old"})
+	}
+	summary, err := newReanalyzer(t, store).Reanalyze(context.Background(), time.Time{}, time.Time{}, "")
+	if err != nil {
+		t.Fatalf("Reanalyze: %v", err)
+	}
+	if summary.Total != 2500 || len(store.updated) != 2500 || store.batches != 3 {
+		t.Errorf("total %d, updated %d in %d batches, want 2500 in 3", summary.Total, len(store.updated), store.batches)
+	}
+}
+
+func TestReanalyzeUpdateError(t *testing.T) {
+	store := &memoryStore{err: errors.New("connection lost")}
+	for i := range 2500 {
+		store.posts = append(store.posts, &model.Post{ID: int64(i), Username: "sledcom_press", Text: "Совещание\nТекст"})
+	}
+	_, err := newReanalyzer(t, store).Reanalyze(context.Background(), time.Time{}, time.Time{}, "")
+	if err == nil || err.Error() != "connection lost" {
+		t.Fatalf("err = %v, want the update error", err)
+	}
+	if store.batches != 1 {
+		t.Errorf("%d batches written after the failure, want to stop at the first", store.batches)
+	}
+}
+
+func TestReanalyzeSummaryWriteText(t *testing.T) {
+	summary := &application.ReanalyzeSummary{
+		Total:    6,
+		Version:  "2025-07-27",
+		Versions: map[string]int{"2025-07-26": 1, "": 2, "2025-07-20": 3},
+	}
+	var first string
+	for range 10 {
+		var b strings.Builder
+		summary.WriteText(&b)
+		if first == "" {
+			first = b.String()
+		} else if b.String() != first {
+			t.Fatalf("output differs between calls:\n%s\n%s", first, b.String())
+		}
+	}
+	want := `  previously analyzed with "": 2
+  previously analyzed with "2025-07-20": 3
+  previously analyzed with "2025-07-26": 1
+`
+	if !strings.Contains(first, want) {
+		t.Errorf("versions are not sorted:\n%s", first)
+	}
+}
@@ -0,0 +1,96 @@
+// Command evaluate measures the analyzer on a labeled dataset.
+//
+//	go run ./cmd/evaluate -dataset testdata/labeled.jsonl -json eval.json -baseline eval-baseline.json
+//
+// It prints precision, recall and F1 of the errand, region and type decisions
+// and the samples the analyzer got wrong. With -baseline it exits with status
+// 1 if any F1 score is lower than in the baseline report.
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/ScrpTrx-Go/GoTGParse/internal/config"
+	"github.com/ScrpTrx-Go/GoTGParse/internal/service/analyzer"
+	"github.com/ScrpTrx-Go/GoTGParse/internal/service/classifier"
+	"github.com/ScrpTrx-Go/GoTGParse/internal/service/evaluation"
+	pkg "github.com/ScrpTrx-Go/GoTGParse/pkg/logger"
+)
+
+func main() {
+	configPath := flag.String("config", "./internal/config/config.yaml", "config file")
+	datasetPath := flag.String("dataset", "", "labeled dataset in JSON Lines")
+	jsonPath := flag.String("json", "", "write the report as JSON to this file")
+	baselinePath := flag.String("baseline", "", "JSON report to compare F1 scores with")
+	tolerance := flag.Float64("tolerance", 0, "allowed F1 decrease against the baseline")
+	flag.Parse()
+
+	if *datasetPath == "" {
+		log.Fatal("-dataset is required")
+	}
+
+	cfg, err := config.LoadConfig(*configPath)
+	if err != nil {
+		log.Fatalf("error load config %v", err)
+	}
+	// Keep the console for the report.
+	cfg.Logger.Level = "warn"
+	zaplogger, err := pkg.NewZapLogger(cfg.Logger)
+	if err != nil {
+		log.Fatalf("error initialize logger: %v", err)
+	}
+	defer zaplogger.Sync()
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	samples, err := evaluation.LoadDataset(*datasetPath)
+	if err != nil {
+		log.Fatalf("error load dataset: %v", err)
+	}
+
+	dictionaries := analyzer.NewFileDictionariesCreator(cfg.Analyzer.DictionariesPath, zaplogger).CreateDictionaries()
+	rules, err := analyzer.NewChannelRules(cfg.Channels)
+	if err != nil {
+		log.Fatalf("invalid channel rules: %v", err)
+	}
+	var normalizer analyzer.Normalizer
+	if cfg.Analyzer.Morphology {
+		normalizer = analyzer.NewRussianStemmer()
+	}
+	classifierPolicy, err := classifier.NewPolicy(cfg.Analyzer.Classifier)
+	if err != nil {
+		log.Fatalf("failed to load classifier: %v", err)
+	}
+	workers := analyzer.NewAnalyzeWorkers(cfg.Analyzer.Workers, zaplogger, dictionaries, rules, normalizer, classifierPolicy, cfg.Confidence.SpecialType)
+
+	report, err := evaluation.Evaluate(ctx, analyzer.NewPostPipeline(zaplogger, workers, cfg.Analyzer.Output), samples, cfg.Confidence)
+	if err != nil {
+		log.Fatalf("evaluation failed: %v", err)
+	}
+	report.WriteText(os.Stdout)
+
+	if *jsonPath != "" {
+		if err := report.Save(*jsonPath); err != nil {
+			log.Fatalf("error save report: %v", err)
+		}
+	}
+
+	if *baselinePath != "" {
+		baseline, err := evaluation.LoadReport(*baselinePath)
+		if err != nil {
+			log.Fatalf("error load baseline: %v", err)
+		}
+		if regressions := report.Regressions(baseline, *tolerance); len(regressions) > 0 {
+			for _, regression := range regressions {
+				log.Printf("regression: %s", regression)
+			}
+			os.Exit(1)
+		}
+	}
+}
@@ -11,8 +11,13 @@ import (
 	"github.com/ScrpTrx-Go/GoTGParse/application"
 	"github.com/ScrpTrx-Go/GoTGParse/internal/config"
 	"github.com/ScrpTrx-Go/GoTGParse/internal/infra/database"
+	"github.com/ScrpTrx-Go/GoTGParse/internal/infra/progress"
 	fetcher "github.com/ScrpTrx-Go/GoTGParse/internal/infra/telegram"
 	"github.com/ScrpTrx-Go/GoTGParse/internal/service/analyzer"
+	"github.com/ScrpTrx-Go/GoTGParse/internal/service/classifier"
+	"github.com/ScrpTrx-Go/GoTGParse/internal/service/dedup"
+	"github.com/ScrpTrx-Go/GoTGParse/internal/service/extractor"
+	"github.com/ScrpTrx-Go/GoTGParse/internal/service/followup"
 	"github.com/ScrpTrx-Go/GoTGParse/internal/service/reporter"
 	pkg "github.com/ScrpTrx-Go/GoTGParse/pkg/logger"
 )
@@ -47,24 +52,43 @@ func main() {
 		}
 	}()
 
-	tdlibFetcher, err := fetcher.NewTDLibFetcher(tdlibclient, zaplogger, config.TDLib)
+	progressReporter, closeProgress, err := progress.NewReporter(config.Progress)
+	if err != nil {
+		zaplogger.Error("progress reporter init error", "err", err)
+		return
+	}
+	defer closeProgress()
+
+	tdlibFetcher, err := fetcher.NewTDLibFetcher(tdlibclient, zaplogger, config.TDLib, progressReporter, config.Progress.Interval)
 	if err != nil {
 		zaplogger.Error("tdlibfetcher init error", err)
 		return
 	}
 
-	dictCreator := analyzer.NewDictionariesCreator()
+	dictCreator := analyzer.NewFileDictionariesCreator(config.Analyzer.DictionariesPath, zaplogger)
 	dictionaries := dictCreator.CreateDictionaries()
-	regions := analyzer.GetRegionKeys(dictionaries.RegionsAllias)
+	rules, err := analyzer.NewChannelRules(config.Channels)
+	if err != nil {
+		zaplogger.Error("invalid channel rules", "err", err)
+		return
+	}
+
+	var normalizer analyzer.Normalizer
+	if config.Analyzer.Morphology {
+		normalizer = analyzer.NewRussianStemmer()
+	}
 
-	workers := make([]analyzer.AnalyzePostWorker, 0, 5)
-	for i := 0; i < 5; i++ {
-		matchCreator := analyzer.NewMatcherCreator(dictionaries, regions)
-		worker := analyzer.NewAnalyzeWorker(matchCreator, zaplogger, regions, *dictionaries)
-		workers = append(workers, worker)
+	classifierPolicy, err := classifier.NewPolicy(config.Analyzer.Classifier)
+	if err != nil {
+		zaplogger.Error("failed to load classifier", "err", err)
+		return
 	}
 
-	postPipeline := analyzer.NewPostPipeline(zaplogger, workers)
+	workers := analyzer.NewAnalyzeWorkers(config.Analyzer.Workers, zaplogger, dictionaries, rules, normalizer, classifierPolicy, config.Confidence.SpecialType)
+
+	postPipeline := analyzer.NewPostPipeline(zaplogger, workers, config.Analyzer.Output, extractor.NewExtractor(), dedup.NewStage())
+	watcher := analyzer.NewDictionaryWatcher(config.Analyzer.DictionariesPath, config.Analyzer.ReloadInterval, zaplogger, workers, rules, normalizer)
+	go watcher.Run(ctx)
 
 	db, err := database.NewPostgresPool(zaplogger, config.DatabaseConfig)
 	if err != nil {
@@ -73,12 +97,19 @@ func main() {
 	}
 	defer db.Pool.Close()
 
-	newReporter := reporter.NewReporter(zaplogger, db)
+	if err := db.Migrate(ctx); err != nil {
+		zaplogger.Error("failed to migrate DB", "err", err)
+		return
+	}
+
+	newReporter := reporter.NewReporter(zaplogger, db, config.Channels, config.Confidence, config.Dedup)
 
 	from := time.Date(2025, time.July, 21, 0, 0, 0, 0, time.Local)
 	to := time.Date(2025, time.July, 22, 0, 0, 0, 0, time.Local)
 
-	app := application.NewApp(tdlibFetcher, postPipeline, zaplogger, db, newReporter)
+	followUps := followup.NewService(db, followup.NewLinker(config.Channels, config.FollowUp))
+
+	app := application.NewApp(tdlibFetcher, postPipeline, zaplogger, db, newReporter, followUps)
 
 	app.Run(ctx, from, to)
 }
@@ -0,0 +1,92 @@
+// Command reanalyze classifies stored posts again with the current
+// dictionaries and channel rules and writes the results back.
+//
+//	go run ./cmd/reanalyze -from 2025-07-21 -to 2025-07-22 -channel sledcom_press
+//
+// Reviews are kept as they are and still take precedence in reports.
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/ScrpTrx-Go/GoTGParse/application"
+	"github.com/ScrpTrx-Go/GoTGParse/internal/config"
+	"github.com/ScrpTrx-Go/GoTGParse/internal/infra/database"
+	"github.com/ScrpTrx-Go/GoTGParse/internal/service/analyzer"
+	"github.com/ScrpTrx-Go/GoTGParse/internal/service/classifier"
+	"github.com/ScrpTrx-Go/GoTGParse/internal/service/dedup"
+	"github.com/ScrpTrx-Go/GoTGParse/internal/service/extractor"
+	pkg "github.com/ScrpTrx-Go/GoTGParse/pkg/logger"
+)
+
+const dateLayout = "2006-01-02"
+
+func main() {
+	configPath := flag.String("config", "./internal/config/config.yaml", "config file")
+	from := flag.String("from", "", "first day, "+dateLayout)
+	to := flag.String("to", "", "day after the last one, "+dateLayout)
+	channel := flag.String("channel", "", "channel username, all channels if empty")
+	flag.Parse()
+
+	fromTime, err := time.ParseInLocation(dateLayout, *from, time.Local)
+	if err != nil {
+		log.Fatalf("invalid -from: %v", err)
+	}
+	toTime, err := time.ParseInLocation(dateLayout, *to, time.Local)
+	if err != nil {
+		log.Fatalf("invalid -to: %v", err)
+	}
+
+	cfg, err := config.LoadConfig(*configPath)
+	if err != nil {
+		log.Fatalf("error load config %v", err)
+	}
+	zaplogger, err := pkg.NewZapLogger(cfg.Logger)
+	if err != nil {
+		log.Fatalf("error initialize logger: %v", err)
+	}
+	defer zaplogger.Sync()
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	dictionaries := analyzer.NewFileDictionariesCreator(cfg.Analyzer.DictionariesPath, zaplogger).CreateDictionaries()
+	rules, err := analyzer.NewChannelRules(cfg.Channels)
+	if err != nil {
+		log.Fatalf("invalid channel rules: %v", err)
+	}
+	var normalizer analyzer.Normalizer
+	if cfg.Analyzer.Morphology {
+		normalizer = analyzer.NewRussianStemmer()
+	}
+	classifierPolicy, err := classifier.NewPolicy(cfg.Analyzer.Classifier)
+	if err != nil {
+		log.Fatalf("failed to load classifier: %v", err)
+	}
+	workers := analyzer.NewAnalyzeWorkers(cfg.Analyzer.Workers, zaplogger, dictionaries, rules, normalizer, classifierPolicy, cfg.Confidence.SpecialType)
+
+	db, err := database.NewPostgresPool(zaplogger, cfg.DatabaseConfig)
+	if err != nil {
+		log.Fatalf("failed to init DB: %v", err)
+	}
+	defer db.Pool.Close()
+	if err := db.Migrate(ctx); err != nil {
+		log.Fatalf("failed to migrate DB: %v", err)
+	}
+
+	reanalyzer := application.NewReanalyzer(analyzer.NewPostPipeline(zaplogger, workers, cfg.Analyzer.Output, extractor.NewExtractor(), dedup.NewStage()), db, zaplogger)
+	summary, err := reanalyzer.Reanalyze(ctx, fromTime, toTime, *channel)
+	if err != nil {
+		zaplogger.Error("Reanalysis failed", "err", err)
+		// os.Exit skips the deferred calls, so the log is flushed here.
+		zaplogger.Sync()
+		log.Fatalf("reanalysis failed: %v", err)
+	}
+	summary.WriteText(os.Stdout)
+}
@@ -0,0 +1,176 @@
+// Command review lets a reviewer go through the posts the analyzer is not sure
+// about and correct them.
+//
+//	go run ./cmd/review queue -from 2025-07-21 -to 2025-07-22 [-missed]
+//	go run ./cmd/review set -channel sledcom_press -id 123 -regions "Омская область" -special=false -reviewer ivanov
+//	go run ./cmd/review set -channel sledcom_press -id 124 -errand=false -note "не поручение"
+//	go run ./cmd/review export -out testdata/reviewed.jsonl
+//
+// queue -missed also lists posts left out of the report that may be errands.
+// A post stored as "maybe errand" before errand confidences shows that error
+// next to its reason.
+// set without -errand, -regions or -special confirms the current
+// classification; only the flags given are applied. Reviews are stored apart from the analyzer results and
+// reports prefer them.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/ScrpTrx-Go/GoTGParse/internal/config"
+	"github.com/ScrpTrx-Go/GoTGParse/internal/infra/database"
+	"github.com/ScrpTrx-Go/GoTGParse/internal/service/analyzer"
+	"github.com/ScrpTrx-Go/GoTGParse/internal/service/review"
+	pkg "github.com/ScrpTrx-Go/GoTGParse/pkg/logger"
+)
+
+const dateLayout = "2006-01-02"
+
+func main() {
+	if len(os.Args) < 2 {
+		log.Fatal("usage: review queue|set|export [flags]")
+	}
+	command, args := os.Args[1], os.Args[2:]
+
+	flags := flag.NewFlagSet(command, flag.ExitOnError)
+	configPath := flags.String("config", "./internal/config/config.yaml", "config file")
+	from := flags.String("from", "", "queue: first day, "+dateLayout)
+	to := flags.String("to", "", "queue: day after the last one, "+dateLayout)
+	channel := flags.String("channel", "", "set: channel username")
+	id := flags.Int64("id", 0, "set: message ID")
+	missed := flags.Bool("missed", false, "queue: also list possible errands left out of the report")
+	errand := flags.Bool("errand", false, "set: the post is an errand, applied only if given")
+	regions := flags.String("regions", "", "set: comma separated regions")
+	special := flags.Bool("special", false, "set: the errand is of the special type, applied only if given")
+	reviewer := flags.String("reviewer", os.Getenv("USER"), "set: reviewer name")
+	note := flags.String("note", "", "set: comment")
+	out := flags.String("out", "", "export: dataset file, stdout if empty")
+	flags.Parse(args)
+
+	cfg, err := config.LoadConfig(*configPath)
+	if err != nil {
+		log.Fatalf("error load config %v", err)
+	}
+	// Keep the console for the command output.
+	cfg.Logger.Level = "warn"
+	zaplogger, err := pkg.NewZapLogger(cfg.Logger)
+	if err != nil {
+		log.Fatalf("error initialize logger: %v", err)
+	}
+	defer zaplogger.Sync()
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	db, err := database.NewPostgresPool(zaplogger, cfg.DatabaseConfig)
+	if err != nil {
+		log.Fatalf("failed to init DB: %v", err)
+	}
+	defer db.Pool.Close()
+	if err := db.Migrate(ctx); err != nil {
+		log.Fatalf("failed to migrate DB: %v", err)
+	}
+
+	service := review.NewService(db, cfg.Confidence)
+
+	switch command {
+	case "queue":
+		fromTime, err := time.ParseInLocation(dateLayout, *from, time.Local)
+		if err != nil {
+			log.Fatalf("invalid -from: %v", err)
+		}
+		toTime, err := time.ParseInLocation(dateLayout, *to, time.Local)
+		if err != nil {
+			log.Fatalf("invalid -to: %v", err)
+		}
+		items, err := service.Queue(ctx, fromTime, toTime, *missed)
+		if err != nil {
+			log.Fatalf("failed to build queue: %v", err)
+		}
+		for _, item := range items {
+			post := item.Post
+			title, _, _ := strings.Cut(post.Text, "\n")
+			reason := item.Reason
+			if post.LegacyErrorType != "" {
+				reason += " (ранее: " + post.LegacyErrorType + ")"
+			}
+			fmt.Printf("%s\t%d\t%s\t%.2f\t%s\t%s\t%s\n", post.Username, post.ID, reason,
+				post.ErrandConfidence, strings.Join(post.Regions, ", "), strings.TrimSpace(title), post.Link)
+		}
+		fmt.Fprintf(os.Stderr, "%d posts to review\n", len(items))
+
+	case "set":
+		if *channel == "" || *id == 0 {
+			log.Fatal("-channel and -id are required")
+		}
+		var override review.Override
+		flags.Visit(func(f *flag.Flag) {
+			switch f.Name {
+			case "errand":
+				override.Errand = errand
+			case "special":
+				override.Special = special
+			case "regions":
+				override.Regions = splitRegions(*regions)
+			}
+		})
+		if override.Regions != nil {
+			known := make(map[string]bool)
+			dict := analyzer.NewFileDictionariesCreator(cfg.Analyzer.DictionariesPath, zaplogger).CreateDictionaries()
+			for _, name := range dict.RegionsAllias {
+				known[name] = true
+			}
+			for _, region := range override.Regions {
+				if !known[region] {
+					log.Fatalf("unknown region %q", region)
+				}
+			}
+		}
+		override.Reviewer = *reviewer
+		override.Note = *note
+		r, err := service.Review(ctx, *channel, *id, override)
+		if err != nil {
+			log.Fatalf("failed to save review: %v", err)
+		}
+		fmt.Printf("%s\t%d\terrand=%v\tspecial=%v\t%s\n", r.Username, r.PostID, r.Errand, r.Special, strings.Join(r.Regions, ", "))
+
+	case "export":
+		w := os.Stdout
+		if *out != "" {
+			f, err := os.Create(*out)
+			if err != nil {
+				log.Fatalf("failed to create %s: %v", *out, err)
+			}
+			defer f.Close()
+			w = f
+		}
+		n, err := service.Export(ctx, w)
+		if err != nil {
+			log.Fatalf("failed to export reviews: %v", err)
+		}
+		fmt.Fprintf(os.Stderr, "%d samples exported\n", n)
+
+	default:
+		log.Fatalf("unknown command %q", command)
+	}
+}
+
+// splitRegions splits a comma separated list; an empty list means the post
+// has no region.
+func splitRegions(list string) []string {
+	regions := []string{}
+	for _, region := range strings.Split(list, ",") {
+		if region = strings.TrimSpace(region); region != "" {
+			regions = append(regions, region)
+		}
+	}
+	return regions
+}
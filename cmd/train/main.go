@@ -0,0 +1,122 @@
+// Command train fits the statistical errand classifier on stored posts and
+// saves the model to the path the analyzer loads it from.
+//
+//	go run ./cmd/train -from 2025-01-01 -to 2025-07-22
+//	go run ./cmd/train -dataset testdata/labeled.jsonl -out ./models/errands.json
+//
+// Reviewed posts are always used, labeled by their review. Posts of the
+// period are used if the stored analysis is sure about them. Samples of a
+// labeled dataset, e.g. one exported by the review command, are added as well.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/ScrpTrx-Go/GoTGParse/internal/config"
+	"github.com/ScrpTrx-Go/GoTGParse/internal/infra/database"
+	"github.com/ScrpTrx-Go/GoTGParse/internal/service/classifier"
+	"github.com/ScrpTrx-Go/GoTGParse/internal/service/evaluation"
+	pkg "github.com/ScrpTrx-Go/GoTGParse/pkg/logger"
+)
+
+const dateLayout = "2006-01-02"
+
+func main() {
+	configPath := flag.String("config", "./internal/config/config.yaml", "config file")
+	from := flag.String("from", "", "first day of stored posts to train on, "+dateLayout+"; reviewed posts only if empty")
+	to := flag.String("to", "", "day after the last one, "+dateLayout)
+	datasetPath := flag.String("dataset", "", "labeled dataset in JSON Lines to train on as well")
+	out := flag.String("out", "", "model file, analyzer.classifier.model_path if empty")
+	minN := flag.Int("min-n", 2, "shortest character n-gram")
+	maxN := flag.Int("max-n", 4, "longest character n-gram")
+	flag.Parse()
+
+	if err := classifier.ValidateNGrams(*minN, *maxN); err != nil {
+		log.Fatalf("invalid -min-n/-max-n: %v", err)
+	}
+
+	cfg, err := config.LoadConfig(*configPath)
+	if err != nil {
+		log.Fatalf("error load config %v", err)
+	}
+	if *out == "" {
+		*out = cfg.Analyzer.Classifier.ModelPath
+	}
+	// Keep the console for the command output.
+	cfg.Logger.Level = "warn"
+	zaplogger, err := pkg.NewZapLogger(cfg.Logger)
+	if err != nil {
+		log.Fatalf("error initialize logger: %v", err)
+	}
+	defer zaplogger.Sync()
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	db, err := database.NewPostgresPool(zaplogger, cfg.DatabaseConfig)
+	if err != nil {
+		log.Fatalf("failed to init DB: %v", err)
+	}
+	defer db.Pool.Close()
+	if err := db.Migrate(ctx); err != nil {
+		log.Fatalf("failed to migrate DB: %v", err)
+	}
+
+	bayes := classifier.NewNaiveBayes(*minN, *maxN)
+
+	posts, err := db.GetReviewedPosts(ctx)
+	if err != nil {
+		log.Fatalf("failed to load reviewed posts: %v", err)
+	}
+	if *from != "" {
+		fromTime, err := time.ParseInLocation(dateLayout, *from, time.Local)
+		if err != nil {
+			log.Fatalf("invalid -from: %v", err)
+		}
+		toTime, err := time.ParseInLocation(dateLayout, *to, time.Local)
+		if err != nil {
+			log.Fatalf("invalid -to: %v", err)
+		}
+		stored, err := db.GetPostsByPeriod(ctx, fromTime, toTime)
+		if err != nil {
+			log.Fatalf("failed to load posts: %v", err)
+		}
+		// Reviewed posts of the period are already loaded.
+		for _, post := range stored {
+			if post.Review == nil {
+				posts = append(posts, post)
+			}
+		}
+	}
+	errands, others := bayes.TrainPosts(posts, cfg.Confidence)
+
+	if *datasetPath != "" {
+		samples, err := evaluation.LoadDataset(*datasetPath)
+		if err != nil {
+			log.Fatalf("error load dataset: %v", err)
+		}
+		for _, sample := range samples {
+			bayes.Train(sample.Text, sample.Errand)
+			if sample.Errand {
+				errands++
+			} else {
+				others++
+			}
+		}
+	}
+
+	if errands == 0 || others == 0 {
+		log.Fatalf("need both errands and other posts to train, got %d errands and %d others", errands, others)
+	}
+	if err := bayes.Save(*out); err != nil {
+		log.Fatalf("failed to save model: %v", err)
+	}
+	fmt.Printf("Trained on %d errands and %d other posts, %d n-grams, saved to %s\n", errands, others, len(bayes.Grams), *out)
+}
@@ -1,5 +1,7 @@
 package config
 
+import "time"
+
 type TDLibConfig struct {
 	UseTestDc           bool       `yaml:"use_test_dc"`
 	DatabaseDirectory   string     `yaml:"database_directory"`
@@ -17,6 +19,7 @@ type TDLibConfig struct {
 	LogLevel            int        `yaml:"log_level"`
 	Usernames           []string   `yaml:"usernames"`
 	GetHistory          GetHistory `yaml:"gethistory"`
+	Fetch               Fetch      `yaml:"fetch"`
 }
 
 type GetHistory struct {
@@ -26,6 +29,141 @@ type GetHistory struct {
 	OnlyLocal     bool  `yaml:"only_local"`
 }
 
+// Fetch.LocalFirst serves history from the TDLib message database first and
+// requests only the missing ranges from the network. It requires
+// use_message_database. Buffer is the capacity of the channel from the
+// fetcher to the analyzer, zero keeps it unbuffered.
+type Fetch struct {
+	Workers    int      `yaml:"workers"`
+	Buffer     int      `yaml:"buffer"`
+	LocalFirst bool     `yaml:"local_first"`
+	Adaptive   Adaptive `yaml:"adaptive"`
+}
+
+// Adaptive bounds the page size and the number of fetch workers. GetHistory.Limit
+// and Fetch.Workers are the upper bounds, they are shrunk on flood errors and
+// grown back after GrowAfter successful requests in a row.
+type Adaptive struct {
+	Enabled    bool  `yaml:"enabled"`
+	MinLimit   int32 `yaml:"min_limit"`
+	MinWorkers int   `yaml:"min_workers"`
+	GrowAfter  int   `yaml:"grow_after"`
+}
+
+// AnalyzerConfig.DictionariesPath points to a YAML or JSON dictionary bundle.
+// If it is empty or invalid, the embedded dictionaries are used. The bundle is
+// checked for changes every ReloadInterval, zero disables hot reload.
+// Morphology matches dictionary terms as whole Russian words in any
+// inflection instead of plain substrings. Workers of zero or less starts one
+// worker per GOMAXPROCS.
+type AnalyzerConfig struct {
+	Workers          int              `yaml:"workers"`
+	DictionariesPath string           `yaml:"dictionaries_path"`
+	ReloadInterval   time.Duration    `yaml:"reload_interval"`
+	Morphology       bool             `yaml:"morphology"`
+	Classifier       ClassifierConfig `yaml:"classifier"`
+	Output           AnalyzerOutput   `yaml:"output"`
+}
+
+// AnalyzerOutput controls the channel from the analyzer to the database.
+// Buffer is its capacity, zero keeps it unbuffered. The workers finish posts
+// in any order; with Ordered the posts of each channel leave in the order the
+// fetcher read them from the history, newest first. Up to ReorderWindow posts
+// per channel are held back while one is missing and the earliest is released
+// when the window is full, so a post delayed by more than the window is passed
+// on late, as is. Posts not fetched in the run, e.g. reanalyzed ones, keep
+// the order they come in.
+type AnalyzerOutput struct {
+	Buffer        int  `yaml:"buffer"`
+	Ordered       bool `yaml:"ordered"`
+	ReorderWindow int  `yaml:"reorder_window"`
+}
+
+// ClassifierConfig enables the statistical errand classifier trained by the
+// train command and saved to ModelPath. Mode "assist" lets it find errands
+// the dictionaries miss, with a confidence low enough for the review queue;
+// mode "replace" uses its probability instead of the dictionaries. A post is
+// an errand for the classifier if the probability is at least Threshold. An
+// empty mode disables the classifier.
+type ClassifierConfig struct {
+	Mode      string  `yaml:"mode"`
+	ModelPath string  `yaml:"model_path"`
+	Threshold float64 `yaml:"threshold"`
+}
+
+// ProgressConfig controls fetch progress events. Interval is the minimal time
+// between two events of the same channel.
+type ProgressConfig struct {
+	Interval      time.Duration `yaml:"interval"`
+	Console       bool          `yaml:"console"`
+	JSONLinesPath string        `yaml:"jsonl_path"`
+}
+
+// ChannelRule describes how posts of a channel are classified and reported.
+// Detector "title" counts how many of Dictionaries match the post title: at
+// least MaybeMatches makes an errand, its confidence grows with the count of
+// matched dictionaries and reaches 1 at MinMatches.
+// Detector "prefix" accepts a post if any of Dictionaries matches its text.
+// Detector "expression" evaluates Expressions in order, the first one that
+// holds decides. ReportSection is the report part the channel feeds:
+// SectionSledcom or SectionIC, a rule without one is rejected.
+type ChannelRule struct {
+	Username      string           `yaml:"username"`
+	Detector      string           `yaml:"detector"`
+	Dictionaries  []string         `yaml:"dictionaries"`
+	MinMatches    int              `yaml:"min_matches"`
+	MaybeMatches  int              `yaml:"maybe_matches"`
+	Expressions   []ExpressionRule `yaml:"expressions"`
+	ReportSection string           `yaml:"report_section"`
+}
+
+// Report sections a channel may feed, see ChannelRule.ReportSection.
+const (
+	SectionSledcom = "sledcom"
+	SectionIC      = "ic"
+)
+
+// ExpressionRule is a named errand detection expression, see
+// analyzer.ParseExpression for the syntax. Maybe lowers the confidence of the
+// posts it accepts so that they go to the review queue.
+type ExpressionRule struct {
+	Name  string `yaml:"name"`
+	When  string `yaml:"when"`
+	Maybe bool   `yaml:"maybe"`
+}
+
+// Confidence holds the thresholds the report applies to analyzer confidences.
+// Errands with at least Report confidence, a region confidence of at least
+// Region for every region and no errors go to the main report. Errands with at
+// least Review confidence that miss one of these go to the review queue, the
+// rest are left out. An errand is counted as special if its type confidence
+// is at least SpecialType.
+type Confidence struct {
+	Report      float64 `yaml:"report"`
+	Review      float64 `yaml:"review"`
+	Region      float64 `yaml:"region"`
+	SpecialType float64 `yaml:"special_type"`
+}
+
+// DedupConfig controls near-duplicate detection. Posts whose fingerprints
+// differ in at most MaxDistance bits and that are published within Window of
+// each other form a cluster; with CountClusters the report counts a cluster
+// once, by its earliest post.
+type DedupConfig struct {
+	CountClusters bool          `yaml:"count_clusters"`
+	MaxDistance   int           `yaml:"max_distance"`
+	Window        time.Duration `yaml:"window"`
+}
+
+// FollowUpConfig controls linking of IC follow-up posts to the sledcom
+// errands they report on: the errand must be published at most Window before
+// the follow-up, and the share of the words of the shorter post found in the
+// other must be at least MinScore.
+type FollowUpConfig struct {
+	Window   time.Duration `yaml:"window"`
+	MinScore float64       `yaml:"min_score"`
+}
+
 type DatabaseConfig struct {
 	DSN string `yaml:"dsn"`
 }
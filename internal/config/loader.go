@@ -2,6 +2,8 @@ package config
 
 import (
 	"os"
+	"runtime"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
@@ -10,6 +12,12 @@ type Config struct {
 	TDLib          TDLibConfig    `yaml:"tdlib"`
 	Logger         LoggerConfig   `yaml:"logger"`
 	DatabaseConfig DatabaseConfig `yaml:"database"`
+	Analyzer       AnalyzerConfig `yaml:"analyzer"`
+	Progress       ProgressConfig `yaml:"progress"`
+	Confidence     Confidence     `yaml:"confidence"`
+	Dedup          DedupConfig    `yaml:"dedup"`
+	FollowUp       FollowUpConfig `yaml:"followup"`
+	Channels       []ChannelRule  `yaml:"channels"`
 }
 
 func LoadConfig(path string) (Config, error) {
@@ -24,5 +32,96 @@ func LoadConfig(path string) (Config, error) {
 		return Config{}, err
 	}
 
+	cfg.applyDefaults()
+
 	return cfg, nil
 }
+
+func (c *Config) applyDefaults() {
+	if c.TDLib.GetHistory.Limit <= 0 || c.TDLib.GetHistory.Limit > 100 {
+		c.TDLib.GetHistory.Limit = 100
+	}
+	if c.TDLib.Fetch.Workers <= 0 {
+		c.TDLib.Fetch.Workers = 5
+	}
+	if c.TDLib.Fetch.Buffer < 0 {
+		c.TDLib.Fetch.Buffer = 0
+	}
+
+	adaptive := &c.TDLib.Fetch.Adaptive
+	if adaptive.MinLimit <= 0 || adaptive.MinLimit > c.TDLib.GetHistory.Limit {
+		adaptive.MinLimit = min(10, c.TDLib.GetHistory.Limit)
+	}
+	if adaptive.MinWorkers <= 0 || adaptive.MinWorkers > c.TDLib.Fetch.Workers {
+		adaptive.MinWorkers = 1
+	}
+	if adaptive.GrowAfter <= 0 {
+		adaptive.GrowAfter = 20
+	}
+
+	if c.Progress.Interval <= 0 {
+		c.Progress.Interval = 2 * time.Second
+	}
+
+	if c.Analyzer.Workers <= 0 {
+		c.Analyzer.Workers = runtime.GOMAXPROCS(0)
+	}
+	if c.Analyzer.Classifier.ModelPath == "" {
+		c.Analyzer.Classifier.ModelPath = "./models/errands.json"
+	}
+	if c.Analyzer.Classifier.Threshold <= 0 || c.Analyzer.Classifier.Threshold > 1 {
+		c.Analyzer.Classifier.Threshold = 0.9
+	}
+	if c.Analyzer.Output.Buffer < 0 {
+		c.Analyzer.Output.Buffer = 0
+	}
+	if c.Analyzer.Output.ReorderWindow <= 0 {
+		c.Analyzer.Output.ReorderWindow = 256
+	}
+
+	if c.Confidence.Report <= 0 {
+		c.Confidence.Report = 0.75
+	}
+	if c.Confidence.Review <= 0 || c.Confidence.Review > c.Confidence.Report {
+		c.Confidence.Review = min(0.5, c.Confidence.Report)
+	}
+	if c.Confidence.SpecialType <= 0 {
+		c.Confidence.SpecialType = 0.5
+	}
+	if c.Confidence.Region <= 0 {
+		c.Confidence.Region = 0.5
+	}
+
+	if c.Dedup.MaxDistance <= 0 {
+		c.Dedup.MaxDistance = 6
+	}
+	if c.Dedup.Window <= 0 {
+		c.Dedup.Window = 72 * time.Hour
+	}
+
+	if c.FollowUp.Window <= 0 {
+		c.FollowUp.Window = 90 * 24 * time.Hour
+	}
+	if c.FollowUp.MinScore <= 0 {
+		c.FollowUp.MinScore = 0.3
+	}
+
+	if len(c.Channels) == 0 {
+		c.Channels = []ChannelRule{
+			{
+				Username:      "sledcom_press",
+				Detector:      "title",
+				Dictionaries:  []string{"prefix", "verbs", "psk"},
+				MinMatches:    3,
+				MaybeMatches:  2,
+				ReportSection: SectionSledcom,
+			},
+			{
+				Username:      "infocentrskrf",
+				Detector:      "prefix",
+				Dictionaries:  []string{"prefix_ic"},
+				ReportSection: SectionIC,
+			},
+		}
+	}
+}
@@ -8,19 +8,45 @@ import (
 )
 
 type PostFetcher interface {
-	RunFetchPipelene(ctx context.Context, from, to time.Time) <-chan *model.Post
+	RunFetchPipelene(ctx context.Context, from, to time.Time) (<-chan *model.Post, *model.FetchStats)
+}
+
+type ProgressReporter interface {
+	Report(event model.FetchProgress)
 }
 
 type PostAnalyzer interface {
-	RunAnalyzePipeline(ctx context.Context, in <-chan *model.Post) <-chan *model.Post
+	RunAnalyzePipeline(ctx context.Context, in <-chan *model.Post) (<-chan *model.Post, *model.AnalysisStats)
 }
 
 type SaverPostgres interface {
 	SaveBatch(ctx context.Context, in <-chan *model.Post) error
+	SaveFetchStats(ctx context.Context, stats *model.FetchStats) error
 	GetMinMaxTimestamps(ctx context.Context) (min time.Time, max time.Time, ok bool, err error)
 	GetPostsByPeriod(ctx context.Context, from, to time.Time) ([]*model.Post, error)
 }
 
+type AnalysisStore interface {
+	StreamPosts(ctx context.Context, from, to time.Time, username string, out chan<- *model.Post) error
+	UpdateAnalysis(ctx context.Context, posts []*model.Post) error
+}
+
+type ReviewStore interface {
+	SaveReview(ctx context.Context, review *model.Review) error
+	GetPost(ctx context.Context, username string, id int64) (*model.Post, error)
+	GetPostsByPeriod(ctx context.Context, from, to time.Time) ([]*model.Post, error)
+	GetReviewedPosts(ctx context.Context) ([]*model.Post, error)
+}
+
+type FollowUpStore interface {
+	GetPostsByPeriod(ctx context.Context, from, to time.Time) ([]*model.Post, error)
+	SaveFollowUps(ctx context.Context, followUps []model.FollowUp) error
+}
+
+type FollowUpLinker interface {
+	Link(ctx context.Context, from, to time.Time) (int, error)
+}
+
 type Reporter interface {
 	GenerateFullReport(ctx context.Context, from, to time.Time) error
 }
@@ -0,0 +1,120 @@
+package model
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// AnalysisStats collects statistics of one analysis run. Workers count on
+// their own and add their counters once they are done, so it is complete once
+// the post channel returned with it is closed. It is safe for concurrent use.
+type AnalysisStats struct {
+	mu        sync.Mutex
+	startedAt time.Time
+	duration  time.Duration
+	workers   []WorkerStats
+}
+
+// WorkerStats holds the counters of one analyzer worker, split by channel.
+type WorkerStats struct {
+	Worker   int
+	Errands  int
+	Skipped  int
+	Channels map[string]ChannelAnalysis
+	Duration time.Duration
+}
+
+// ChannelAnalysis counts the analyzed posts of a channel.
+type ChannelAnalysis struct {
+	Username string
+	Errands  int
+	Skipped  int
+}
+
+func NewAnalysisStats() *AnalysisStats {
+	return &AnalysisStats{startedAt: time.Now()}
+}
+
+func NewWorkerStats(worker int) WorkerStats {
+	return WorkerStats{
+		Worker:   worker,
+		Channels: make(map[string]ChannelAnalysis),
+	}
+}
+
+// Add counts an analyzed post of the channel.
+func (w *WorkerStats) Add(username string, errand bool) {
+	ch := w.Channels[username]
+	ch.Username = username
+	if errand {
+		w.Errands++
+		ch.Errands++
+	} else {
+		w.Skipped++
+		ch.Skipped++
+	}
+	w.Channels[username] = ch
+}
+
+func (s *AnalysisStats) AddWorker(w WorkerStats) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.workers = append(s.workers, w)
+}
+
+func (s *AnalysisStats) Finish() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.duration = time.Since(s.startedAt)
+}
+
+func (s *AnalysisStats) Duration() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.duration
+}
+
+// Workers returns the statistics of the workers sorted by worker number.
+func (s *AnalysisStats) Workers() []WorkerStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	result := make([]WorkerStats, len(s.workers))
+	copy(result, s.workers)
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Worker < result[j].Worker
+	})
+	return result
+}
+
+// Channels sums the counters of all workers by channel, sorted by username.
+func (s *AnalysisStats) Channels() []ChannelAnalysis {
+	byChannel := make(map[string]ChannelAnalysis)
+	for _, w := range s.Workers() {
+		for username, ch := range w.Channels {
+			total := byChannel[username]
+			total.Username = username
+			total.Errands += ch.Errands
+			total.Skipped += ch.Skipped
+			byChannel[username] = total
+		}
+	}
+	result := make([]ChannelAnalysis, 0, len(byChannel))
+	for _, ch := range byChannel {
+		result = append(result, ch)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Username < result[j].Username
+	})
+	return result
+}
+
+// Totals sums the counters of all workers.
+func (s *AnalysisStats) Totals() ChannelAnalysis {
+	var total ChannelAnalysis
+	for _, w := range s.Workers() {
+		total.Errands += w.Errands
+		total.Skipped += w.Skipped
+	}
+	return total
+}
@@ -0,0 +1,37 @@
+package model
+
+import (
+	"strings"
+	"time"
+)
+
+// ErrandDetails holds the structured fields extracted from an errand text.
+type ErrandDetails struct {
+	Articles  []Article `json:"articles,omitempty"`
+	Addressee string    `json:"addressee,omitempty"`
+	Actions   []string  `json:"actions,omitempty"`
+	// Deadline is the deadline as written; DeadlineDate is set if it names a
+	// calendar date.
+	Deadline     string     `json:"deadline,omitempty"`
+	DeadlineDate *time.Time `json:"deadline_date,omitempty"`
+}
+
+// Article is an article of the Criminal Code, with its part and point if
+// given.
+type Article struct {
+	Point   string `json:"point,omitempty"`
+	Part    string `json:"part,omitempty"`
+	Article string `json:"article"`
+}
+
+func (a Article) String() string {
+	var b strings.Builder
+	if a.Point != "" {
+		b.WriteString("п. «" + a.Point + "» ")
+	}
+	if a.Part != "" {
+		b.WriteString("ч. " + a.Part + " ")
+	}
+	b.WriteString("ст. " + a.Article + " УК РФ")
+	return b.String()
+}
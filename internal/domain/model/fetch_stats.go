@@ -0,0 +1,160 @@
+package model
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	FilterUnsupportedContent = "unsupported_content"
+	FilterEmptyText          = "empty_text"
+)
+
+// FetchStats collects statistics of one fetch run. It is safe for concurrent
+// use and is complete once the post channel returned with it is closed.
+type FetchStats struct {
+	mu        sync.Mutex
+	startedAt time.Time
+	duration  time.Duration
+	channels  map[string]*ChannelStats
+}
+
+// ChannelStats holds the counters of a single channel within a fetch run.
+type ChannelStats struct {
+	Username       string
+	PagesRead      int
+	LocalPagesRead int
+	MessagesSeen   int
+	Fetched        int
+	Filtered       map[string]int
+	LinkFailures   int
+	Errors         int
+	Duration       time.Duration
+}
+
+func NewFetchStats() *FetchStats {
+	return &FetchStats{
+		startedAt: time.Now(),
+		channels:  make(map[string]*ChannelStats),
+	}
+}
+
+func (s *FetchStats) channel(username string) *ChannelStats {
+	ch, ok := s.channels[username]
+	if !ok {
+		ch = &ChannelStats{
+			Username: username,
+			Filtered: make(map[string]int),
+		}
+		s.channels[username] = ch
+	}
+	return ch
+}
+
+// AddPage counts a history page. Pages served from the local TDLib database
+// are counted separately from network ones.
+func (s *FetchStats) AddPage(username string, messages int, local bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ch := s.channel(username)
+	if local {
+		ch.LocalPagesRead++
+	} else {
+		ch.PagesRead++
+	}
+	ch.MessagesSeen += messages
+}
+
+func (s *FetchStats) AddFetched(username string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.channel(username).Fetched++
+}
+
+func (s *FetchStats) AddFiltered(username, reason string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.channel(username).Filtered[reason]++
+}
+
+func (s *FetchStats) AddLinkFailure(username string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.channel(username).LinkFailures++
+}
+
+func (s *FetchStats) AddError(username string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.channel(username).Errors++
+}
+
+func (s *FetchStats) FinishChannel(username string, d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.channel(username).Duration = d
+}
+
+func (s *FetchStats) Finish() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.duration = time.Since(s.startedAt)
+}
+
+func (s *FetchStats) StartedAt() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.startedAt
+}
+
+func (s *FetchStats) Duration() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.duration
+}
+
+// Channels returns copies of the per-channel statistics sorted by username.
+func (s *FetchStats) Channels() []ChannelStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	result := make([]ChannelStats, 0, len(s.channels))
+	for _, ch := range s.channels {
+		c := *ch
+		c.Filtered = make(map[string]int, len(ch.Filtered))
+		for reason, n := range ch.Filtered {
+			c.Filtered[reason] = n
+		}
+		result = append(result, c)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Username < result[j].Username
+	})
+	return result
+}
+
+// Totals sums the statistics of all channels.
+func (s *FetchStats) Totals() ChannelStats {
+	total := ChannelStats{Filtered: make(map[string]int)}
+	for _, ch := range s.Channels() {
+		total.PagesRead += ch.PagesRead
+		total.LocalPagesRead += ch.LocalPagesRead
+		total.MessagesSeen += ch.MessagesSeen
+		total.Fetched += ch.Fetched
+		total.LinkFailures += ch.LinkFailures
+		total.Errors += ch.Errors
+		for reason, n := range ch.Filtered {
+			total.Filtered[reason] += n
+		}
+	}
+	total.Duration = s.Duration()
+	return total
+}
+
+func (c ChannelStats) FilteredTotal() int {
+	total := 0
+	for _, n := range c.Filtered {
+		total += n
+	}
+	return total
+}
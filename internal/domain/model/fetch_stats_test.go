@@ -0,0 +1,47 @@
+package model_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/ScrpTrx-Go/GoTGParse/internal/domain/model"
+)
+
+func TestFetchStatsConcurrent(t *testing.T) {
+	stats := model.NewFetchStats()
+	usernames := []string{"sledcom_press", "infocentrskrf"}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		for _, username := range usernames {
+			wg.Add(1)
+			go func(username string) {
+				defer wg.Done()
+				stats.AddPage(username, 10, false)
+				stats.AddFetched(username)
+				stats.AddFiltered(username, model.FilterEmptyText)
+				stats.AddLinkFailure(username)
+			}(username)
+		}
+	}
+	wg.Wait()
+	stats.Finish()
+
+	channels := stats.Channels()
+	if len(channels) != len(usernames) {
+		t.Fatalf("expected %d channels, got %d", len(usernames), len(channels))
+	}
+	for _, ch := range channels {
+		if ch.PagesRead != 50 || ch.MessagesSeen != 500 || ch.Fetched != 50 || ch.LinkFailures != 50 {
+			t.Fatalf("unexpected counters for %s: %+v", ch.Username, ch)
+		}
+		if ch.Filtered[model.FilterEmptyText] != 50 {
+			t.Fatalf("unexpected filtered for %s: %v", ch.Username, ch.Filtered)
+		}
+	}
+
+	totals := stats.Totals()
+	if totals.Fetched != 100 || totals.FilteredTotal() != 100 {
+		t.Fatalf("unexpected totals: %+v", totals)
+	}
+}
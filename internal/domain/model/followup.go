@@ -0,0 +1,15 @@
+package model
+
+import "time"
+
+// FollowUp links a post that reports the outcome of an errand, e.g. "по
+// поручению Председателя ... возбуждено уголовное дело", to the errand.
+type FollowUp struct {
+	Username       string    `json:"username"`
+	PostID         int64     `json:"post_id"`
+	Link           string    `json:"link"`
+	PublishedAt    time.Time `json:"published_at"`
+	ErrandUsername string    `json:"errand_username"`
+	ErrandID       int64     `json:"errand_id"`
+	Score          float64   `json:"score"`
+}
@@ -0,0 +1,7 @@
+package model
+
+// Locality is a city and the federal subject it belongs to.
+type Locality struct {
+	Name   string `json:"name" yaml:"name"`
+	Region string `json:"region" yaml:"region"`
+}
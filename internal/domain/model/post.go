@@ -3,12 +3,32 @@ package model
 import "time"
 
 type Post struct {
-	ID         int64
-	Link       string
-	Text       string
-	Timestamp  time.Time
-	Username   string
-	Regions    []string
-	ErrandType bool
-	ErrorType  string
+	ID                int64
+	Link              string
+	Text              string
+	Timestamp         time.Time
+	Username          string
+	IsErrand          bool
+	Regions           []string
+	SecondaryRegions  []string
+	Localities        []Locality
+	ErrandType        bool
+	Categories        []string
+	ErrorType         string
+	DictionaryVersion string
+	MatchedRule       string
+	Trace             *AnalysisTrace
+	ErrandConfidence  float64
+	TypeConfidence    float64
+	RegionConfidence  map[string]float64
+	Details           *ErrandDetails
+	Fingerprint       uint64
+	Review            *Review
+	FollowUp          *FollowUp
+	// LegacyErrorType is the error the post was stored with before errand
+	// confidences replaced it, e.g. "maybe errand".
+	LegacyErrorType string
+	// Sequence numbers the posts of a channel from 1 in the order the fetcher
+	// read them; 0 if the post was not fetched in this run.
+	Sequence uint64
 }
@@ -0,0 +1,20 @@
+package model
+
+import "time"
+
+// FetchProgress is a progress event of one channel within a fetch run. The
+// history is read backward, so progress is measured by how far OldestReached
+// went from To towards From.
+type FetchProgress struct {
+	Username      string        `json:"username"`
+	Time          time.Time     `json:"time"`
+	From          time.Time     `json:"from"`
+	To            time.Time     `json:"to"`
+	OldestReached time.Time     `json:"oldest_reached"`
+	Messages      int           `json:"messages"`
+	Rate          float64       `json:"messages_per_second"`
+	Percent       float64       `json:"percent"`
+	Elapsed       time.Duration `json:"elapsed_ns"`
+	ETA           time.Duration `json:"eta_ns"`
+	Done          bool          `json:"done"`
+}
@@ -0,0 +1,16 @@
+package model
+
+import "time"
+
+// Review is a reviewer's decision on a post. It is stored apart from the
+// analyzer results and takes precedence over them in reports.
+type Review struct {
+	Username   string
+	PostID     int64
+	Errand     bool
+	Regions    []string
+	Special    bool
+	Reviewer   string
+	Note       string
+	ReviewedAt time.Time
+}
@@ -0,0 +1,39 @@
+package model
+
+// AnalysisTrace explains how the analyzer classified a post.
+type AnalysisTrace struct {
+	Title               string        `json:"title"`
+	ErrandBody          string        `json:"errand_body"`
+	ErrandBodyParagraph int           `json:"errand_body_paragraph"`
+	Matches             []TraceMatch  `json:"matches,omitempty"`
+	Regions             []TraceRegion `json:"regions,omitempty"`
+	// Classifier is the errand probability given by the statistical
+	// classifier, if one is configured.
+	Classifier *float64 `json:"classifier,omitempty"`
+}
+
+// TraceMatch is a dictionary term found in a region of the post text. Offset
+// is counted in characters of that region.
+type TraceMatch struct {
+	Dictionary string `json:"dictionary"`
+	Region     string `json:"region"`
+	Term       string `json:"term"`
+	Offset     int    `json:"offset"`
+}
+
+// Statuses of region candidates.
+const (
+	RegionChosen    = "chosen"
+	RegionSecondary = "secondary"
+	// RegionDiscarded is only found in traces stored before candidates that
+	// were not chosen were kept as secondary regions.
+	RegionDiscarded = "discarded"
+)
+
+// TraceRegion is a region candidate with the decision taken about it.
+type TraceRegion struct {
+	Name   string `json:"name"`
+	Source string `json:"source"`
+	Status string `json:"status"`
+	Reason string `json:"reason,omitempty"`
+}
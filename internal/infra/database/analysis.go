@@ -0,0 +1,82 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ScrpTrx-Go/GoTGParse/internal/domain/model"
+	"github.com/jackc/pgx/v5"
+)
+
+// StreamPosts sends the posts of the period to out, oldest first, and closes
+// it. An empty username selects all channels.
+func (d *Database) StreamPosts(ctx context.Context, from, to time.Time, username string, out chan<- *model.Post) error {
+	defer close(out)
+
+	query := postSelect + `
+			  WHERE p.timestamp BETWEEN $1 AND $2 AND ($3 = '' OR p.username = $3)
+			  ORDER BY p.timestamp ASC`
+
+	rows, err := d.Pool.Query(ctx, query, from, to, username)
+	if err != nil {
+		return fmt.Errorf("failed to query posts by period: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		post, err := scanPost(rows)
+		if err != nil {
+			d.Log.Warn("Failed to scan post", "err", err)
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case out <- post:
+		}
+	}
+	return rows.Err()
+}
+
+// UpdateAnalysis writes the analyzer results of already stored posts.
+func (d *Database) UpdateAnalysis(ctx context.Context, posts []*model.Post) error {
+	query := `UPDATE posts SET
+			  is_errand = $3, regions = $4, localities = $5, errand_type = $6, error_type = $7, dictionary_version = $8,
+			  matched_rule = $9, trace = $10, errand_confidence = $11, type_confidence = $12, region_confidence = $13,
+			  details = $14, categories = $15, fingerprint = $16,
+			  secondary_regions = $17
+			  WHERE username = $1 AND id = $2`
+
+	const batchSize = 1000
+	for start := 0; start < len(posts); start += batchSize {
+		batch := &pgx.Batch{}
+		for _, p := range posts[start:min(start+batchSize, len(posts))] {
+			batch.Queue(query,
+				p.Username,
+				p.ID,
+				p.IsErrand,
+				p.Regions,
+				p.Localities,
+				p.ErrandType,
+				p.ErrorType,
+				p.DictionaryVersion,
+				p.MatchedRule,
+				p.Trace,
+				p.ErrandConfidence,
+				p.TypeConfidence,
+				p.RegionConfidence,
+				p.Details,
+				p.Categories,
+				int64(p.Fingerprint),
+				p.SecondaryRegions,
+			)
+		}
+		if err := d.Pool.SendBatch(ctx, batch).Close(); err != nil {
+			d.Log.Error("Failed to update analysis", "err", err)
+			return err
+		}
+	}
+	d.Log.Info("Updated analysis of posts", "count", len(posts))
+	return nil
+}
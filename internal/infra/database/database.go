@@ -53,16 +53,29 @@ func (d *Database) SaveBatch(ctx context.Context, in <-chan *model.Post) error {
 			p.Text,
 			p.Timestamp,
 			p.Username,
+			p.IsErrand,
 			p.Regions,
+			p.Localities,
 			p.ErrandType,
 			p.ErrorType,
+			p.DictionaryVersion,
+			p.MatchedRule,
+			p.Trace,
+			p.ErrandConfidence,
+			p.TypeConfidence,
+			p.RegionConfidence,
+			p.Details,
+			p.Categories,
+			int64(p.Fingerprint),
+			p.SecondaryRegions,
 		})
 	}
 
 	_, err := d.Pool.CopyFrom(
 		ctx,
 		pgx.Identifier{"posts"},
-		[]string{"id", "link", "text", "timestamp", "username", "regions", "errand_type", "error_type"},
+		[]string{"id", "link", "text", "timestamp", "username", "is_errand", "regions", "localities", "errand_type", "error_type", "dictionary_version", "matched_rule", "trace",
+			"errand_confidence", "type_confidence", "region_confidence", "details", "categories", "fingerprint", "secondary_regions"},
 		pgx.CopyFromRows(rows),
 	)
 	if err != nil {
@@ -74,6 +87,37 @@ func (d *Database) SaveBatch(ctx context.Context, in <-chan *model.Post) error {
 	return nil
 }
 
+func (d *Database) SaveFetchStats(ctx context.Context, stats *model.FetchStats) error {
+	query := `INSERT INTO fetch_stats
+			  (run_started_at, username, pages_read, local_pages_read, messages_seen, fetched, filtered, link_failures, errors, duration_ms)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`
+
+	batch := &pgx.Batch{}
+	for _, ch := range stats.Channels() {
+		batch.Queue(query,
+			stats.StartedAt(),
+			ch.Username,
+			ch.PagesRead,
+			ch.LocalPagesRead,
+			ch.MessagesSeen,
+			ch.Fetched,
+			ch.Filtered,
+			ch.LinkFailures,
+			ch.Errors,
+			ch.Duration.Milliseconds(),
+		)
+	}
+	if batch.Len() == 0 {
+		return nil
+	}
+
+	if err := d.Pool.SendBatch(ctx, batch).Close(); err != nil {
+		d.Log.Error("Failed to save fetch stats", "err", err)
+		return err
+	}
+	return nil
+}
+
 func (d *Database) GetMinMaxTimestamps(ctx context.Context) (min time.Time, max time.Time, ok bool, err error) {
 	query := `SELECT MIN(timestamp), MAX(timestamp) FROM posts`
 	row := d.Pool.QueryRow(ctx, query)
@@ -90,36 +134,131 @@ func (d *Database) GetMinMaxTimestamps(ctx context.Context) (min time.Time, max
 	return *minPtr, *maxPtr, true, nil
 }
 
+// postSelect selects posts together with their reviews and the earliest
+// follow-up of each errand; queries append their conditions to it and scan the
+// rows with scanPosts.
+const postSelect = `SELECT p.id, p.link, p.text, p.timestamp, p.username, p.is_errand, p.regions, p.localities, p.errand_type, p.error_type,
+			  p.dictionary_version, p.matched_rule, p.trace, p.errand_confidence, p.type_confidence, p.region_confidence, p.details, p.categories, p.fingerprint, p.secondary_regions,
+			  COALESCE(p.legacy_error_type, ''),
+			  r.errand, r.regions, r.special, r.reviewer, r.note, r.reviewed_at,
+			  f.username, f.post_id, f.link, f.timestamp, f.score
+			  FROM posts p
+			  LEFT JOIN reviews r ON r.username = p.username AND r.post_id = p.id
+			  LEFT JOIN LATERAL (
+				  SELECT fu.username, fu.post_id, fp.link, fp.timestamp, fu.score
+				  FROM followups fu
+				  JOIN posts fp ON fp.username = fu.username AND fp.id = fu.post_id
+				  WHERE fu.errand_username = p.username AND fu.errand_id = p.id
+				  ORDER BY fp.timestamp ASC
+				  LIMIT 1
+			  ) f ON TRUE`
+
 func (d *Database) GetPostsByPeriod(ctx context.Context, from, to time.Time) ([]*model.Post, error) {
-	query := `SELECT id, link, text, timestamp, username, regions, errand_type, error_type
-			  FROM posts
-			  WHERE timestamp BETWEEN $1 AND $2
-			  ORDER BY timestamp ASC`
+	query := postSelect + `
+			  WHERE p.timestamp BETWEEN $1 AND $2
+			  ORDER BY p.timestamp ASC`
 
 	rows, err := d.Pool.Query(ctx, query, from, to)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query posts by period: %w", err)
 	}
+	return d.scanPosts(rows), nil
+}
+
+func (d *Database) scanPosts(rows pgx.Rows) []*model.Post {
 	defer rows.Close()
 
 	var posts []*model.Post
 	for rows.Next() {
-		var post model.Post
-		err := rows.Scan(
-			&post.ID,
-			&post.Link,
-			&post.Text,
-			&post.Timestamp,
-			&post.Username,
-			&post.Regions,
-			&post.ErrandType,
-			&post.ErrorType,
-		)
+		post, err := scanPost(rows)
 		if err != nil {
 			d.Log.Warn("Failed to scan post", "err", err)
 			continue
 		}
-		posts = append(posts, &post)
+		posts = append(posts, post)
+	}
+	if err := rows.Err(); err != nil {
+		d.Log.Warn("Failed to read posts", "err", err)
+	}
+	return posts
+}
+
+func scanPost(rows pgx.Rows) (*model.Post, error) {
+	var post model.Post
+	var reviewed struct {
+		errand, special *bool
+		regions         []string
+		reviewer, note  *string
+		reviewedAt      *time.Time
+	}
+	var followUp struct {
+		username, link *string
+		postID         *int64
+		publishedAt    *time.Time
+		score          *float64
+	}
+	// Fingerprints are stored as signed BIGINT.
+	var fingerprint int64
+	err := rows.Scan(
+		&post.ID,
+		&post.Link,
+		&post.Text,
+		&post.Timestamp,
+		&post.Username,
+		&post.IsErrand,
+		&post.Regions,
+		&post.Localities,
+		&post.ErrandType,
+		&post.ErrorType,
+		&post.DictionaryVersion,
+		&post.MatchedRule,
+		&post.Trace,
+		&post.ErrandConfidence,
+		&post.TypeConfidence,
+		&post.RegionConfidence,
+		&post.Details,
+		&post.Categories,
+		&fingerprint,
+		&post.SecondaryRegions,
+		&post.LegacyErrorType,
+		&reviewed.errand,
+		&reviewed.regions,
+		&reviewed.special,
+		&reviewed.reviewer,
+		&reviewed.note,
+		&reviewed.reviewedAt,
+		&followUp.username,
+		&followUp.postID,
+		&followUp.link,
+		&followUp.publishedAt,
+		&followUp.score,
+	)
+	if err != nil {
+		return nil, err
+	}
+	post.Fingerprint = uint64(fingerprint)
+	if reviewed.errand != nil {
+		post.Review = &model.Review{
+			Username:   post.Username,
+			PostID:     post.ID,
+			Errand:     *reviewed.errand,
+			Regions:    reviewed.regions,
+			Special:    *reviewed.special,
+			Reviewer:   *reviewed.reviewer,
+			Note:       *reviewed.note,
+			ReviewedAt: *reviewed.reviewedAt,
+		}
+	}
+	if followUp.username != nil {
+		post.FollowUp = &model.FollowUp{
+			Username:       *followUp.username,
+			PostID:         *followUp.postID,
+			Link:           *followUp.link,
+			PublishedAt:    *followUp.publishedAt,
+			ErrandUsername: post.Username,
+			ErrandID:       post.ID,
+			Score:          *followUp.score,
+		}
 	}
-	return posts, nil
+	return &post, nil
 }
@@ -0,0 +1,29 @@
+package database
+
+import (
+	"context"
+
+	"github.com/ScrpTrx-Go/GoTGParse/internal/domain/model"
+	"github.com/jackc/pgx/v5"
+)
+
+// SaveFollowUps stores links of follow-up posts to errands, replacing the
+// previous link of a follow-up.
+func (d *Database) SaveFollowUps(ctx context.Context, followUps []model.FollowUp) error {
+	query := `INSERT INTO followups (username, post_id, errand_username, errand_id, score, linked_at)
+			  VALUES ($1, $2, $3, $4, $5, now())
+			  ON CONFLICT (username, post_id) DO UPDATE SET
+			  errand_username = EXCLUDED.errand_username, errand_id = EXCLUDED.errand_id,
+			  score = EXCLUDED.score, linked_at = EXCLUDED.linked_at`
+
+	batch := &pgx.Batch{}
+	for _, f := range followUps {
+		batch.Queue(query, f.Username, f.PostID, f.ErrandUsername, f.ErrandID, f.Score)
+	}
+	if err := d.Pool.SendBatch(ctx, batch).Close(); err != nil {
+		d.Log.Error("Failed to save follow-ups", "err", err)
+		return err
+	}
+	d.Log.Info("Saved follow-ups", "count", len(followUps))
+	return nil
+}
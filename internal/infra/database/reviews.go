@@ -0,0 +1,63 @@
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ScrpTrx-Go/GoTGParse/internal/domain/model"
+)
+
+// SaveReview stores the review of a post, replacing the previous one.
+func (d *Database) SaveReview(ctx context.Context, review *model.Review) error {
+	query := `INSERT INTO reviews (username, post_id, errand, regions, special, reviewer, note, reviewed_at)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			  ON CONFLICT (username, post_id) DO UPDATE SET
+			  errand = EXCLUDED.errand, regions = EXCLUDED.regions, special = EXCLUDED.special,
+			  reviewer = EXCLUDED.reviewer, note = EXCLUDED.note, reviewed_at = EXCLUDED.reviewed_at`
+
+	_, err := d.Pool.Exec(ctx, query,
+		review.Username,
+		review.PostID,
+		review.Errand,
+		review.Regions,
+		review.Special,
+		review.Reviewer,
+		review.Note,
+		review.ReviewedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save review of %s/%d: %w", review.Username, review.PostID, err)
+	}
+	return nil
+}
+
+// GetPost returns the post of the channel with the given message ID, or nil if
+// there is none.
+func (d *Database) GetPost(ctx context.Context, username string, id int64) (*model.Post, error) {
+	query := postSelect + `
+			  WHERE p.username = $1 AND p.id = $2
+			  LIMIT 1`
+
+	rows, err := d.Pool.Query(ctx, query, username, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query post %s/%d: %w", username, id, err)
+	}
+	posts := d.scanPosts(rows)
+	if len(posts) == 0 {
+		return nil, nil
+	}
+	return posts[0], nil
+}
+
+// GetReviewedPosts returns all posts that have a review.
+func (d *Database) GetReviewedPosts(ctx context.Context) ([]*model.Post, error) {
+	query := postSelect + `
+			  WHERE r.post_id IS NOT NULL
+			  ORDER BY p.timestamp ASC`
+
+	rows, err := d.Pool.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query reviewed posts: %w", err)
+	}
+	return d.scanPosts(rows), nil
+}
@@ -0,0 +1,91 @@
+package database
+
+import (
+	"context"
+	"fmt"
+)
+
+var migrations = []string{
+	`CREATE TABLE IF NOT EXISTS posts (
+		id          BIGINT NOT NULL,
+		link        TEXT NOT NULL DEFAULT '',
+		text        TEXT NOT NULL,
+		timestamp   TIMESTAMPTZ NOT NULL,
+		username    TEXT NOT NULL,
+		regions     TEXT[],
+		errand_type BOOLEAN NOT NULL DEFAULT FALSE,
+		error_type  TEXT NOT NULL DEFAULT ''
+	)`,
+	`CREATE TABLE IF NOT EXISTS fetch_stats (
+		run_started_at TIMESTAMPTZ NOT NULL,
+		username       TEXT NOT NULL,
+		pages_read     INTEGER NOT NULL,
+		messages_seen  INTEGER NOT NULL,
+		fetched        INTEGER NOT NULL,
+		filtered       JSONB NOT NULL,
+		link_failures  INTEGER NOT NULL,
+		errors         INTEGER NOT NULL,
+		duration_ms    BIGINT NOT NULL,
+		PRIMARY KEY (run_started_at, username)
+	)`,
+	`ALTER TABLE fetch_stats ADD COLUMN IF NOT EXISTS local_pages_read INTEGER NOT NULL DEFAULT 0`,
+	`ALTER TABLE posts ADD COLUMN IF NOT EXISTS dictionary_version TEXT NOT NULL DEFAULT ''`,
+	`ALTER TABLE posts ADD COLUMN IF NOT EXISTS matched_rule TEXT NOT NULL DEFAULT ''`,
+	`ALTER TABLE posts ADD COLUMN IF NOT EXISTS trace JSONB`,
+	// Posts saved before confidences were introduced get them from the
+	// boolean decisions they were stored with; "maybe errand" is no longer an
+	// error but a low confidence. The replaced error is kept in
+	// legacy_error_type and shown in the review queue.
+	`ALTER TABLE posts ADD COLUMN IF NOT EXISTS errand_confidence DOUBLE PRECISION`,
+	`ALTER TABLE posts ADD COLUMN IF NOT EXISTS type_confidence DOUBLE PRECISION`,
+	`ALTER TABLE posts ADD COLUMN IF NOT EXISTS legacy_error_type TEXT`,
+	`UPDATE posts SET
+		legacy_error_type = CASE WHEN error_type = 'maybe errand' THEN error_type ELSE legacy_error_type END,
+		errand_confidence = CASE WHEN error_type = 'maybe errand' THEN 0.5 ELSE 1 END,
+		type_confidence = CASE WHEN errand_type THEN 1 ELSE 0 END,
+		error_type = CASE WHEN error_type = 'maybe errand' THEN '' ELSE error_type END
+	 WHERE errand_confidence IS NULL OR type_confidence IS NULL`,
+	`ALTER TABLE posts ALTER COLUMN errand_confidence SET NOT NULL`,
+	`ALTER TABLE posts ALTER COLUMN type_confidence SET NOT NULL`,
+	`ALTER TABLE posts ADD COLUMN IF NOT EXISTS region_confidence JSONB`,
+	`CREATE TABLE IF NOT EXISTS reviews (
+		username    TEXT NOT NULL,
+		post_id     BIGINT NOT NULL,
+		errand      BOOLEAN NOT NULL,
+		regions     TEXT[],
+		special     BOOLEAN NOT NULL,
+		reviewer    TEXT NOT NULL DEFAULT '',
+		note        TEXT NOT NULL DEFAULT '',
+		reviewed_at TIMESTAMPTZ NOT NULL,
+		PRIMARY KEY (username, post_id)
+	)`,
+	// Only errands were stored before non-errand posts were kept too.
+	`ALTER TABLE posts ADD COLUMN IF NOT EXISTS is_errand BOOLEAN NOT NULL DEFAULT TRUE`,
+	`ALTER TABLE posts ADD COLUMN IF NOT EXISTS localities JSONB`,
+	`ALTER TABLE posts ADD COLUMN IF NOT EXISTS details JSONB`,
+	`ALTER TABLE posts ADD COLUMN IF NOT EXISTS categories TEXT[]`,
+	`ALTER TABLE posts ADD COLUMN IF NOT EXISTS fingerprint BIGINT NOT NULL DEFAULT 0`,
+	`CREATE TABLE IF NOT EXISTS followups (
+		username        TEXT NOT NULL,
+		post_id         BIGINT NOT NULL,
+		errand_username TEXT NOT NULL,
+		errand_id       BIGINT NOT NULL,
+		score           DOUBLE PRECISION NOT NULL,
+		linked_at       TIMESTAMPTZ NOT NULL,
+		PRIMARY KEY (username, post_id)
+	)`,
+	`CREATE INDEX IF NOT EXISTS followups_errand_idx ON followups (errand_username, errand_id)`,
+	`ALTER TABLE posts ADD COLUMN IF NOT EXISTS secondary_regions TEXT[]`,
+	// Reanalysis and reviews update and read posts one by one.
+	`CREATE INDEX IF NOT EXISTS posts_username_id_idx ON posts (username, id)`,
+}
+
+func (d *Database) Migrate(ctx context.Context) error {
+	for i, stmt := range migrations {
+		if _, err := d.Pool.Exec(ctx, stmt); err != nil {
+			return fmt.Errorf("migration %d failed: %w", i, err)
+		}
+	}
+	d.Log.Info("Database schema is up to date", "migrations", len(migrations))
+	return nil
+}
@@ -0,0 +1,103 @@
+package progress
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/ScrpTrx-Go/GoTGParse/internal/config"
+	"github.com/ScrpTrx-Go/GoTGParse/internal/domain/contracts"
+	"github.com/ScrpTrx-Go/GoTGParse/internal/domain/model"
+)
+
+// ConsoleReporter prints every event as a single progress line.
+type ConsoleReporter struct {
+	mu  sync.Mutex
+	out io.Writer
+}
+
+func NewConsoleReporter(out io.Writer) *ConsoleReporter {
+	return &ConsoleReporter{out: out}
+}
+
+func (c *ConsoleReporter) Report(event model.FetchProgress) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	state := fmt.Sprintf("ETA %s", event.ETA.Round(time.Second))
+	if event.Done {
+		state = fmt.Sprintf("done in %s", event.Elapsed.Round(time.Second))
+	}
+	fmt.Fprintf(c.out, "[%s] %5.1f%% reached %s of %s..%s, %d msgs, %.1f msg/s, %s\n",
+		event.Username,
+		event.Percent,
+		event.OldestReached.Format("2006-01-02 15:04"),
+		event.From.Format("2006-01-02"),
+		event.To.Format("2006-01-02"),
+		event.Messages,
+		event.Rate,
+		state,
+	)
+}
+
+// JSONLinesReporter writes every event as a JSON object on its own line.
+type JSONLinesReporter struct {
+	mu   sync.Mutex
+	file *os.File
+	enc  *json.Encoder
+}
+
+func NewJSONLinesReporter(path string) (*JSONLinesReporter, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("open progress file: %w", err)
+	}
+	return &JSONLinesReporter{
+		file: file,
+		enc:  json.NewEncoder(file),
+	}, nil
+}
+
+func (j *JSONLinesReporter) Report(event model.FetchProgress) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	_ = j.enc.Encode(event)
+}
+
+func (j *JSONLinesReporter) Close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.file.Close()
+}
+
+// MultiReporter fans events out to several reporters.
+type MultiReporter []contracts.ProgressReporter
+
+func (m MultiReporter) Report(event model.FetchProgress) {
+	for _, r := range m {
+		r.Report(event)
+	}
+}
+
+// NewReporter builds the reporters enabled in cfg. The returned close function
+// releases the files opened by them.
+func NewReporter(cfg config.ProgressConfig) (contracts.ProgressReporter, func() error, error) {
+	reporters := MultiReporter{}
+	closeFn := func() error { return nil }
+
+	if cfg.Console {
+		reporters = append(reporters, NewConsoleReporter(os.Stdout))
+	}
+	if cfg.JSONLinesPath != "" {
+		jsonl, err := NewJSONLinesReporter(cfg.JSONLinesPath)
+		if err != nil {
+			return nil, nil, err
+		}
+		reporters = append(reporters, jsonl)
+		closeFn = jsonl.Close
+	}
+	return reporters, closeFn, nil
+}
@@ -0,0 +1,98 @@
+package progress_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ScrpTrx-Go/GoTGParse/internal/config"
+	"github.com/ScrpTrx-Go/GoTGParse/internal/domain/model"
+	"github.com/ScrpTrx-Go/GoTGParse/internal/infra/progress"
+)
+
+func testEvent(done bool) model.FetchProgress {
+	from := time.Date(2025, 7, 21, 0, 0, 0, 0, time.UTC)
+	return model.FetchProgress{
+		Username:      "sledcom_press",
+		Time:          from.Add(36 * time.Hour),
+		From:          from,
+		To:            from.Add(48 * time.Hour),
+		OldestReached: from.Add(36 * time.Hour),
+		Messages:      42,
+		Rate:          3.25,
+		Percent:       25,
+		Elapsed:       90*time.Second + 400*time.Millisecond,
+		ETA:           4*time.Minute + 31*time.Second + 600*time.Millisecond,
+		Done:          done,
+	}
+}
+
+func TestConsoleReporter(t *testing.T) {
+	for _, tc := range []struct {
+		done bool
+		want string
+	}{
+		{false, "[sledcom_press]  25.0% reached 2025-07-22 12:00 of 2025-07-21..2025-07-23, 42 msgs, 3.2 msg/s, ETA 4m32s\n"},
+		{true, "[sledcom_press]  25.0% reached 2025-07-22 12:00 of 2025-07-21..2025-07-23, 42 msgs, 3.2 msg/s, done in 1m30s\n"},
+	} {
+		var out bytes.Buffer
+		progress.NewConsoleReporter(&out).Report(testEvent(tc.done))
+		if out.String() != tc.want {
+			t.Errorf("done=%v:\n got %q\nwant %q", tc.done, out.String(), tc.want)
+		}
+	}
+}
+
+func TestJSONLinesReporter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "progress.jsonl")
+	reporter, err := progress.NewJSONLinesReporter(path)
+	if err != nil {
+		t.Fatalf("NewJSONLinesReporter: %v", err)
+	}
+	reporter.Report(testEvent(false))
+	reporter.Report(testEvent(true))
+	if err := reporter.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("lines = %d, want 2", len(lines))
+	}
+	for i, line := range lines {
+		var event model.FetchProgress
+		if err := json.Unmarshal([]byte(line), &event); err != nil {
+			t.Fatalf("line %d: %v", i, err)
+		}
+		if want := testEvent(i == 1); !event.Time.Equal(want.Time) || event.Messages != want.Messages || event.ETA != want.ETA || event.Done != want.Done {
+			t.Errorf("line %d = %+v, want %+v", i, event, want)
+		}
+	}
+}
+
+func TestNewReporter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "progress.jsonl")
+	reporter, closeFn, err := progress.NewReporter(config.ProgressConfig{JSONLinesPath: path})
+	if err != nil {
+		t.Fatalf("NewReporter: %v", err)
+	}
+	reporter.Report(testEvent(true))
+	if err := closeFn(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	if data, _ := os.ReadFile(path); bytes.Count(data, []byte("\n")) != 1 {
+		t.Errorf("file = %q, want one event", data)
+	}
+
+	if _, _, err := progress.NewReporter(config.ProgressConfig{JSONLinesPath: filepath.Join(t.TempDir(), "missing", "p.jsonl")}); err == nil {
+		t.Error("expected an error for a file in a missing directory")
+	}
+}
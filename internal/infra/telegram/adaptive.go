@@ -0,0 +1,183 @@
+package fetcher
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ScrpTrx-Go/GoTGParse/internal/config"
+	pkg "github.com/ScrpTrx-Go/GoTGParse/pkg/logger"
+	"github.com/zelenin/go-tdlib/client"
+)
+
+const defaultFloodWait = 5 * time.Second
+
+var retryAfterRe = regexp.MustCompile(`retry after (\d+)`)
+
+// throttle limits the page size of GetChatHistory and the number of
+// concurrently working fetch workers. In adaptive mode both are halved on
+// flood errors and grown back while the API stays healthy.
+type throttle struct {
+	mu         sync.Mutex
+	log        pkg.Logger
+	adaptive   bool
+	limit      int32
+	minLimit   int32
+	maxLimit   int32
+	workers    int
+	minWorkers int
+	maxWorkers int
+	active     int
+	successes  int
+	growAfter  int
+	wake       chan struct{}
+}
+
+func newThrottle(cfg config.TDLibConfig, log pkg.Logger) *throttle {
+	return &throttle{
+		log:        log,
+		adaptive:   cfg.Fetch.Adaptive.Enabled,
+		limit:      cfg.GetHistory.Limit,
+		minLimit:   cfg.Fetch.Adaptive.MinLimit,
+		maxLimit:   cfg.GetHistory.Limit,
+		workers:    cfg.Fetch.Workers,
+		minWorkers: cfg.Fetch.Adaptive.MinWorkers,
+		maxWorkers: cfg.Fetch.Workers,
+		growAfter:  cfg.Fetch.Adaptive.GrowAfter,
+		wake:       make(chan struct{}),
+	}
+}
+
+func (t *throttle) Limit() int32 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.limit
+}
+
+func (t *throttle) MaxWorkers() int {
+	return t.maxWorkers
+}
+
+// Acquire blocks until the number of active workers is below the current
+// worker count.
+func (t *throttle) Acquire(ctx context.Context) error {
+	for {
+		t.mu.Lock()
+		if t.active < t.workers {
+			t.active++
+			t.mu.Unlock()
+			return nil
+		}
+		wake := t.wake
+		t.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-wake:
+		}
+	}
+}
+
+func (t *throttle) Release() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.active--
+	t.broadcast()
+}
+
+func (t *throttle) OnSuccess() {
+	if !t.adaptive {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.successes++
+	if t.successes < t.growAfter {
+		return
+	}
+	t.successes = 0
+	if t.limit == t.maxLimit && t.workers == t.maxWorkers {
+		return
+	}
+	t.limit = min(t.limit*2, t.maxLimit)
+	t.workers = min(t.workers+1, t.maxWorkers)
+	t.broadcast()
+	t.log.Info("Fetch throttle grown", "limit", t.limit, "workers", t.workers)
+}
+
+func (t *throttle) OnFlood() {
+	if !t.adaptive {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.successes = 0
+	t.limit = max(t.limit/2, t.minLimit)
+	t.workers = max(t.workers/2, t.minWorkers)
+	t.log.Warn("Fetch throttle shrunk", "limit", t.limit, "workers", t.workers)
+}
+
+func (t *throttle) broadcast() {
+	close(t.wake)
+	t.wake = make(chan struct{})
+}
+
+// floodWait reports whether err is a TDLib flood error and how long to wait
+// before retrying.
+func floodWait(err error) (time.Duration, bool) {
+	var respErr client.ResponseError
+	if !errors.As(err, &respErr) || respErr.Err == nil || respErr.Err.Code != 429 {
+		return 0, false
+	}
+	match := retryAfterRe.FindStringSubmatch(respErr.Err.Message)
+	if match == nil {
+		return defaultFloodWait, true
+	}
+	seconds, err := strconv.Atoi(match[1])
+	if err != nil {
+		return defaultFloodWait, true
+	}
+	return time.Duration(seconds) * time.Second, true
+}
+
+// withFloodRetry calls fn until it returns something other than a flood error,
+// waiting as requested by TDLib between attempts.
+func withFloodRetry[T any](ctx context.Context, t *throttle, log pkg.Logger, fn func() (T, error)) (T, error) {
+	for {
+		result, err := fn()
+		wait, flood := floodWait(err)
+		if !flood {
+			if err == nil {
+				t.OnSuccess()
+			}
+			return result, err
+		}
+
+		t.OnFlood()
+		log.Warn("Flood wait", "retry_after", wait.String())
+		select {
+		case <-ctx.Done():
+			var zero T
+			return zero, ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+// withWorkerSlot is withFloodRetry for calls limited by the worker count. Each
+// attempt holds a worker slot, which is given back while a flood error is
+// waited out, so that a worker count lowered by OnFlood takes effect at once.
+func withWorkerSlot[T any](ctx context.Context, t *throttle, log pkg.Logger, fn func() (T, error)) (T, error) {
+	return withFloodRetry(ctx, t, log, func() (T, error) {
+		if err := t.Acquire(ctx); err != nil {
+			var zero T
+			return zero, err
+		}
+		defer t.Release()
+		return fn()
+	})
+}
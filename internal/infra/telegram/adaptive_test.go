@@ -0,0 +1,139 @@
+package fetcher
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ScrpTrx-Go/GoTGParse/internal/config"
+	pkg "github.com/ScrpTrx-Go/GoTGParse/pkg/logger"
+	"github.com/zelenin/go-tdlib/client"
+)
+
+func newTestThrottle(t *testing.T, adaptive bool) *throttle {
+	t.Helper()
+	log, err := pkg.NewZapLogger(config.LoggerConfig{Level: "error", FilePath: filepath.Join(t.TempDir(), "app.log")})
+	if err != nil {
+		t.Fatalf("NewZapLogger: %v", err)
+	}
+	var cfg config.TDLibConfig
+	cfg.GetHistory.Limit = 100
+	cfg.Fetch.Workers = 4
+	cfg.Fetch.Adaptive = config.Adaptive{Enabled: adaptive, MinLimit: 10, MinWorkers: 1, GrowAfter: 3}
+	return newThrottle(cfg, log)
+}
+
+func TestThrottleShrinkAndGrow(t *testing.T) {
+	th := newTestThrottle(t, true)
+
+	for _, want := range []struct {
+		limit   int32
+		workers int
+	}{{50, 2}, {25, 1}, {12, 1}, {10, 1}, {10, 1}} {
+		th.OnFlood()
+		if th.Limit() != want.limit || th.workers != want.workers {
+			t.Fatalf("after flood: limit %d, workers %d; want %d, %d", th.Limit(), th.workers, want.limit, want.workers)
+		}
+	}
+
+	for i := 0; i < 2; i++ {
+		th.OnSuccess()
+	}
+	if th.Limit() != 10 {
+		t.Fatalf("grown before %d successes: limit %d", th.growAfter, th.Limit())
+	}
+	for _, want := range []struct {
+		limit   int32
+		workers int
+	}{{20, 2}, {40, 3}, {80, 4}, {100, 4}, {100, 4}} {
+		for i := 0; i < th.growAfter; i++ {
+			th.OnSuccess()
+		}
+		if th.Limit() != want.limit || th.workers != want.workers {
+			t.Fatalf("after growth: limit %d, workers %d; want %d, %d", th.Limit(), th.workers, want.limit, want.workers)
+		}
+	}
+}
+
+func TestThrottleFloodResetsSuccesses(t *testing.T) {
+	th := newTestThrottle(t, true)
+	th.OnFlood()
+	th.OnSuccess()
+	th.OnSuccess()
+	th.OnFlood()
+	th.OnSuccess()
+	th.OnSuccess()
+	if th.Limit() != 25 {
+		t.Errorf("limit = %d, want 25: successes before a flood must not count", th.Limit())
+	}
+}
+
+func TestThrottleDisabled(t *testing.T) {
+	th := newTestThrottle(t, false)
+	th.OnFlood()
+	if th.Limit() != 100 || th.workers != 4 {
+		t.Errorf("limit %d, workers %d; want them unchanged", th.Limit(), th.workers)
+	}
+}
+
+func TestThrottleAcquireLimit(t *testing.T) {
+	th := newTestThrottle(t, true)
+	th.OnFlood() // two workers
+
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		if err := th.Acquire(ctx); err != nil {
+			t.Fatalf("Acquire %d: %v", i, err)
+		}
+	}
+	short, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	if err := th.Acquire(short); err == nil {
+		t.Fatal("acquired a third slot with two workers")
+	}
+
+	acquired := make(chan error, 1)
+	go func() { acquired <- th.Acquire(ctx) }()
+	th.Release()
+	select {
+	case err := <-acquired:
+		if err != nil {
+			t.Fatalf("Acquire after Release: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Release did not wake a waiting worker")
+	}
+}
+
+func TestWorkerSlotReleasedDuringFloodWait(t *testing.T) {
+	th := newTestThrottle(t, true)
+	th.workers, th.minWorkers = 1, 1
+	ctx := context.Background()
+
+	calls := 0
+	done := make(chan error, 1)
+	go func() {
+		_, err := withWorkerSlot(ctx, th, th.log, func() (string, error) {
+			calls++
+			if calls == 1 {
+				return "", client.ResponseError{Err: &client.Error{Code: 429, Message: "Too Many Requests: retry after 1"}}
+			}
+			return "ok", nil
+		})
+		done <- err
+	}()
+
+	// The only slot must be free while the flood error is waited out.
+	time.Sleep(100 * time.Millisecond)
+	short, cancel := context.WithTimeout(ctx, 500*time.Millisecond)
+	defer cancel()
+	if err := th.Acquire(short); err != nil {
+		t.Fatalf("slot held during flood wait: %v", err)
+	}
+	th.Release()
+
+	if err := <-done; err != nil || calls != 2 {
+		t.Errorf("withWorkerSlot: err %v after %d calls", err, calls)
+	}
+}
@@ -0,0 +1,178 @@
+package fetcher
+
+import (
+	"context"
+	"time"
+
+	"github.com/zelenin/go-tdlib/client"
+)
+
+// Channel message identifiers are server identifiers shifted left by 20 bits.
+const serverMessageIDShift = 20
+
+// historyPage describes where paging of the chat history starts. Paging stops
+// at the first message older than the period or, if stopAtID is not zero, at
+// the first message with identifier stopAtID or lower.
+type historyPage struct {
+	chatID        int64
+	fromMessageID int64
+	offset        int32
+	onlyLocal     bool
+	stopAtID      int64
+}
+
+// pageHistory pages the chat history backward and passes messages of the
+// period to emit until emit returns false. boundaryID is the identifier of
+// the message paging stopped at: the first one older than the period or at
+// stopAtID. It is zero if paging stopped at the end of the history or was
+// interrupted.
+func (f *TDLibFetcher) pageHistory(ctx context.Context, page historyPage, from, to time.Time, emit func(*client.Message) bool, onPage func(messages int, local bool)) (boundaryID int64, err error) {
+	for {
+		select {
+		case <-ctx.Done():
+			f.log.Warn("Context cancelled in GetHistoryByPeriod")
+			return 0, nil
+		default:
+		}
+
+		history, err := withFloodRetry(ctx, f.throttle, f.log, func() (*client.Messages, error) {
+			return f.client.GetChatHistory(&client.GetChatHistoryRequest{
+				ChatId:        page.chatID,
+				FromMessageId: page.fromMessageID,
+				Offset:        page.offset,
+				Limit:         f.throttle.Limit(),
+				OnlyLocal:     page.onlyLocal,
+			})
+		})
+		if err != nil {
+			f.log.Error("GetChatHistory failed", "chat_id", page.chatID, "only_local", page.onlyLocal, "err", err)
+			return 0, err
+		}
+		if onPage != nil {
+			onPage(len(history.Messages), page.onlyLocal)
+		}
+		if len(history.Messages) == 0 {
+			f.log.Info("Reached end of history", "chat_id", page.chatID, "only_local", page.onlyLocal)
+			return 0, nil
+		}
+
+		for _, msg := range history.Messages {
+			if page.stopAtID != 0 && msg.Id <= page.stopAtID {
+				return msg.Id, nil
+			}
+			t := time.Unix(int64(msg.Date), 0)
+			if t.After(to) {
+				continue
+			}
+			if t.Before(from) {
+				f.log.Info("Reached start of period", "chat_id", page.chatID, "only_local", page.onlyLocal)
+				return msg.Id, nil
+			}
+			if !emit(msg) {
+				return 0, nil
+			}
+		}
+
+		page.fromMessageID = history.Messages[len(history.Messages)-1].Id
+		page.offset = 0
+	}
+}
+
+// getHistoryLocalFirst reads the period from the TDLib message database and
+// requests from the network only the ranges that are missing locally, see
+// planGaps.
+func (f *TDLibFetcher) getHistoryLocalFirst(ctx context.Context, start historyPage, from, to time.Time, send func(*client.Message) bool, onPage func(messages int, local bool)) error {
+	localPage := start
+	localPage.onlyLocal = true
+
+	var local []*client.Message
+	boundaryID, err := f.pageHistory(ctx, localPage, from, to, func(msg *client.Message) bool {
+		local = append(local, msg)
+		return true
+	}, onPage)
+	if err != nil {
+		f.log.Warn("Local history read failed, fetching from network", "chat_id", start.chatID, "err", err)
+		local, boundaryID = nil, 0
+	}
+	if ctx.Err() != nil {
+		return nil
+	}
+
+	ids := make([]int64, len(local))
+	for i, msg := range local {
+		ids[i] = msg.Id
+	}
+	gaps := planGaps(start, ids, boundaryID)
+
+	next := 0
+	for i := 0; i <= len(local); i++ {
+		for ; next < len(gaps) && gaps[next].before == i; next++ {
+			if _, err := f.pageHistory(ctx, gaps[next].page, from, to, send, onPage); err != nil {
+				return err
+			}
+		}
+		if i < len(local) && !send(local[i]) {
+			return nil
+		}
+	}
+
+	f.log.Info("Local history served", "chat_id", start.chatID, "local", len(local), "network_ranges", len(gaps))
+	return nil
+}
+
+// historyGap is a range of the period missing locally. It is fetched from the
+// network before the local message with index before, or after all of them
+// if before equals their count.
+type historyGap struct {
+	before int
+	page   historyPage
+}
+
+// planGaps finds the ranges of the period that the local messages, newest
+// first, do not cover. A range is considered missing when two neighbouring
+// messages do not have consecutive server identifiers; deleted messages cost
+// an extra request, but never a lost message. boundaryID is the message the
+// local read stopped at, zero if it ran out of local history, then the tail
+// is requested without a stop.
+func planGaps(start historyPage, local []int64, boundaryID int64) []historyGap {
+	if len(local) == 0 {
+		return []historyGap{{before: 0, page: start}}
+	}
+
+	var gaps []historyGap
+	if start.fromMessageID != local[0] {
+		gaps = append(gaps, historyGap{before: 0, page: historyPage{
+			chatID:        start.chatID,
+			fromMessageID: start.fromMessageID,
+			offset:        start.offset,
+			stopAtID:      local[0],
+		}})
+	}
+	for i := 1; i < len(local); i++ {
+		if !contiguous(local[i-1], local[i]) {
+			gaps = append(gaps, historyGap{before: i, page: historyPage{
+				chatID:        start.chatID,
+				fromMessageID: local[i-1],
+				stopAtID:      local[i],
+			}})
+		}
+	}
+	last := local[len(local)-1]
+	if boundaryID == 0 || !contiguous(last, boundaryID) {
+		gaps = append(gaps, historyGap{before: len(local), page: historyPage{
+			chatID:        start.chatID,
+			fromMessageID: last,
+			stopAtID:      boundaryID,
+		}})
+	}
+	return gaps
+}
+
+// contiguous reports whether older directly precedes newer in the channel.
+func contiguous(newer, older int64) bool {
+	const mask = 1<<serverMessageIDShift - 1
+	if newer&mask != 0 || older&mask != 0 {
+		return false
+	}
+	return newer>>serverMessageIDShift-older>>serverMessageIDShift == 1
+}
@@ -0,0 +1,86 @@
+package fetcher
+
+import (
+	"reflect"
+	"testing"
+)
+
+func msgID(server int64) int64 {
+	return server << serverMessageIDShift
+}
+
+func TestContiguous(t *testing.T) {
+	for _, tc := range []struct {
+		name         string
+		newer, older int64
+		want         bool
+	}{
+		{"consecutive", msgID(11), msgID(10), true},
+		{"gap", msgID(12), msgID(10), false},
+		{"same", msgID(10), msgID(10), false},
+		{"reversed", msgID(10), msgID(11), false},
+		{"local newer", msgID(11) + 1, msgID(10), false},
+		{"local older", msgID(11), msgID(10) + 1, false},
+	} {
+		if got := contiguous(tc.newer, tc.older); got != tc.want {
+			t.Errorf("%s: contiguous(%d, %d) = %v, want %v", tc.name, tc.newer, tc.older, got, tc.want)
+		}
+	}
+}
+
+func TestPlanGaps(t *testing.T) {
+	start := historyPage{chatID: 1, fromMessageID: msgID(20), offset: -1}
+	for _, tc := range []struct {
+		name     string
+		local    []int64
+		boundary int64
+		want     []historyGap
+	}{
+		{
+			name:  "empty local",
+			local: nil,
+			want:  []historyGap{{before: 0, page: start}},
+		},
+		{
+			name:     "complete",
+			local:    []int64{msgID(20), msgID(19), msgID(18)},
+			boundary: msgID(17),
+			want:     nil,
+		},
+		{
+			name:     "head gap",
+			local:    []int64{msgID(18), msgID(17)},
+			boundary: msgID(16),
+			want: []historyGap{{before: 0, page: historyPage{
+				chatID: 1, fromMessageID: msgID(20), offset: -1, stopAtID: msgID(18),
+			}}},
+		},
+		{
+			name:     "middle gap",
+			local:    []int64{msgID(20), msgID(19), msgID(15), msgID(14)},
+			boundary: msgID(13),
+			want: []historyGap{{before: 2, page: historyPage{
+				chatID: 1, fromMessageID: msgID(19), stopAtID: msgID(15),
+			}}},
+		},
+		{
+			name:     "tail gap",
+			local:    []int64{msgID(20), msgID(19)},
+			boundary: msgID(12),
+			want: []historyGap{{before: 2, page: historyPage{
+				chatID: 1, fromMessageID: msgID(19), stopAtID: msgID(12),
+			}}},
+		},
+		{
+			name:  "end of local history",
+			local: []int64{msgID(20), msgID(19)},
+			want: []historyGap{{before: 2, page: historyPage{
+				chatID: 1, fromMessageID: msgID(19),
+			}}},
+		},
+	} {
+		if got := planGaps(start, tc.local, tc.boundary); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: planGaps = %+v, want %+v", tc.name, got, tc.want)
+		}
+	}
+}
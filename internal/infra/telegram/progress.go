@@ -0,0 +1,94 @@
+package fetcher
+
+import (
+	"time"
+
+	"github.com/ScrpTrx-Go/GoTGParse/internal/domain/contracts"
+	"github.com/ScrpTrx-Go/GoTGParse/internal/domain/model"
+)
+
+// progressTracker turns posts of one channel into progress events. It is used
+// by a single goroutine.
+type progressTracker struct {
+	reporter contracts.ProgressReporter
+	interval time.Duration
+	username string
+	from     time.Time
+	to       time.Time
+	start    time.Time
+	lastSent time.Time
+	oldest   time.Time
+	messages int
+	now      func() time.Time
+}
+
+func newProgressTracker(reporter contracts.ProgressReporter, interval time.Duration, username string, from, to time.Time) *progressTracker {
+	now := time.Now()
+	return &progressTracker{
+		reporter: reporter,
+		interval: interval,
+		username: username,
+		from:     from,
+		to:       to,
+		start:    now,
+		lastSent: now,
+		oldest:   to,
+		now:      time.Now,
+	}
+}
+
+func (p *progressTracker) Observe(post *model.Post) {
+	if p.reporter == nil {
+		return
+	}
+	p.messages++
+	if post.Timestamp.Before(p.oldest) {
+		p.oldest = post.Timestamp
+	}
+	if p.now().Sub(p.lastSent) >= p.interval {
+		p.report(false)
+	}
+}
+
+func (p *progressTracker) Done() {
+	if p.reporter == nil {
+		return
+	}
+	p.report(true)
+}
+
+func (p *progressTracker) report(done bool) {
+	now := p.now()
+	p.lastSent = now
+	elapsed := now.Sub(p.start)
+
+	fraction := 1.0
+	if window := p.to.Sub(p.from); window > 0 && !done {
+		fraction = float64(p.to.Sub(p.oldest)) / float64(window)
+		fraction = min(max(fraction, 0), 1)
+	}
+
+	var rate float64
+	if elapsed > 0 {
+		rate = float64(p.messages) / elapsed.Seconds()
+	}
+
+	var eta time.Duration
+	if fraction > 0 && fraction < 1 {
+		eta = time.Duration(float64(elapsed) * (1 - fraction) / fraction)
+	}
+
+	p.reporter.Report(model.FetchProgress{
+		Username:      p.username,
+		Time:          now,
+		From:          p.from,
+		To:            p.to,
+		OldestReached: p.oldest,
+		Messages:      p.messages,
+		Rate:          rate,
+		Percent:       fraction * 100,
+		Elapsed:       elapsed,
+		ETA:           eta,
+		Done:          done,
+	})
+}
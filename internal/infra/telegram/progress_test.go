@@ -0,0 +1,109 @@
+package fetcher
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ScrpTrx-Go/GoTGParse/internal/domain/model"
+)
+
+type recordReporter struct {
+	events []model.FetchProgress
+}
+
+func (r *recordReporter) Report(event model.FetchProgress) {
+	r.events = append(r.events, event)
+}
+
+// fakeClock returns a tracker clock starting at start and a function that
+// moves it forward.
+func fakeClock(start time.Time) (func() time.Time, func(time.Duration)) {
+	now := start
+	return func() time.Time { return now }, func(d time.Duration) { now = now.Add(d) }
+}
+
+func newTestTracker(interval time.Duration, from, to time.Time) (*progressTracker, *recordReporter, func(time.Duration)) {
+	start := time.Date(2025, 7, 22, 12, 0, 0, 0, time.UTC)
+	clock, advance := fakeClock(start)
+	reporter := &recordReporter{}
+	p := newProgressTracker(reporter, interval, "sledcom_press", from, to)
+	p.now, p.start, p.lastSent = clock, start, start
+	return p, reporter, advance
+}
+
+func TestProgressTrackerArithmetic(t *testing.T) {
+	from := time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(100 * time.Hour)
+	for _, tc := range []struct {
+		name    string
+		from    time.Time
+		oldest  time.Time
+		posts   int
+		elapsed time.Duration
+		done    bool
+		percent float64
+		eta     time.Duration
+		rate    float64
+	}{
+		{"quarter", from, to.Add(-25 * time.Hour), 10, 10 * time.Second, false, 25, 30 * time.Second, 1},
+		{"half", from, to.Add(-50 * time.Hour), 20, 20 * time.Second, false, 50, 20 * time.Second, 1},
+		{"nothing reached", from, to, 0, 5 * time.Second, false, 0, 0, 0},
+		{"past the start", from, from.Add(-time.Hour), 4, 2 * time.Second, false, 100, 0, 2},
+		{"empty window", to, to.Add(-time.Hour), 1, time.Second, false, 100, 0, 1},
+		{"no time elapsed", from, to.Add(-50 * time.Hour), 1, 0, false, 50, 0, 0},
+		{"done", from, to.Add(-25 * time.Hour), 10, 10 * time.Second, true, 100, 0, 1},
+	} {
+		p, reporter, advance := newTestTracker(time.Hour, tc.from, to)
+		for i := 0; i < tc.posts; i++ {
+			p.Observe(&model.Post{Timestamp: tc.oldest})
+		}
+		advance(tc.elapsed)
+		p.report(tc.done)
+
+		event := reporter.events[len(reporter.events)-1]
+		if event.Percent != tc.percent || event.ETA != tc.eta || event.Rate != tc.rate || event.Done != tc.done {
+			t.Errorf("%s: percent %v, ETA %v, rate %v, done %v; want %v, %v, %v, %v", tc.name,
+				event.Percent, event.ETA, event.Rate, event.Done, tc.percent, tc.eta, tc.rate, tc.done)
+		}
+		if event.Messages != tc.posts || event.Elapsed != tc.elapsed {
+			t.Errorf("%s: messages %d, elapsed %v", tc.name, event.Messages, event.Elapsed)
+		}
+	}
+}
+
+func TestProgressTrackerInterval(t *testing.T) {
+	from := time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)
+	p, reporter, advance := newTestTracker(2*time.Second, from, from.Add(time.Hour))
+
+	post := &model.Post{Timestamp: from.Add(30 * time.Minute)}
+	for _, step := range []struct {
+		advance time.Duration
+		events  int
+	}{
+		{time.Second, 0},
+		{500 * time.Millisecond, 0},
+		{500 * time.Millisecond, 1},
+		{time.Second, 1},
+		{2 * time.Second, 2},
+	} {
+		advance(step.advance)
+		p.Observe(post)
+		if len(reporter.events) != step.events {
+			t.Fatalf("after %d messages: %d events, want %d", p.messages, len(reporter.events), step.events)
+		}
+	}
+
+	p.Done()
+	if n := len(reporter.events); n != 3 || !reporter.events[n-1].Done {
+		t.Errorf("Done did not emit a final event: %+v", reporter.events)
+	}
+}
+
+func TestProgressTrackerWithoutReporter(t *testing.T) {
+	p := newProgressTracker(nil, 0, "sledcom_press", time.Time{}, time.Now())
+	p.Observe(&model.Post{})
+	p.Done()
+	if p.messages != 0 {
+		t.Errorf("messages = %d, want posts ignored without a reporter", p.messages)
+	}
+}
@@ -2,28 +2,32 @@ package fetcher
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/ScrpTrx-Go/GoTGParse/internal/config"
+	"github.com/ScrpTrx-Go/GoTGParse/internal/domain/contracts"
 	"github.com/ScrpTrx-Go/GoTGParse/internal/domain/model"
 	pkg "github.com/ScrpTrx-Go/GoTGParse/pkg/logger"
 	"github.com/zelenin/go-tdlib/client"
 )
 
 type TDLibFetcher struct {
-	client        *client.Client
-	me            *client.User
-	log           pkg.Logger
-	cfg           config.TDLibConfig
-	totalFetched  int
-	totalFiltered int
-	totalErrors   int
+	client   *client.Client
+	me       *client.User
+	log      pkg.Logger
+	cfg      config.TDLibConfig
+	throttle *throttle
+	progress contracts.ProgressReporter
+	interval time.Duration
 }
 
-func NewTDLibFetcher(tdlibClient *client.Client, log pkg.Logger, cfg config.TDLibConfig) (*TDLibFetcher, error) {
+// NewTDLibFetcher creates a fetcher. progress may be nil, then no progress
+// events are reported.
+func NewTDLibFetcher(tdlibClient *client.Client, log pkg.Logger, cfg config.TDLibConfig, progress contracts.ProgressReporter, progressInterval time.Duration) (*TDLibFetcher, error) {
 	me, err := tdlibClient.GetMe()
 	if err != nil {
 		return nil, fmt.Errorf("GetMe error: %w", err)
@@ -31,15 +35,19 @@ func NewTDLibFetcher(tdlibClient *client.Client, log pkg.Logger, cfg config.TDLi
 	log.Info("Authorized successfully", "user_id", me.Id, "first_name", me.FirstName)
 	log.Info("New TDLibFetcher was created")
 	return &TDLibFetcher{
-		client: tdlibClient,
-		me:     me,
-		log:    log,
-		cfg:    cfg,
+		client:   tdlibClient,
+		me:       me,
+		log:      log,
+		cfg:      cfg,
+		throttle: newThrottle(cfg, log),
+		progress: progress,
+		interval: progressInterval,
 	}, nil
 }
 
-func (f *TDLibFetcher) RunFetchPipelene(ctx context.Context, from, to time.Time) <-chan *model.Post {
-	out := make(chan *model.Post)
+func (f *TDLibFetcher) RunFetchPipelene(ctx context.Context, from, to time.Time) (<-chan *model.Post, *model.FetchStats) {
+	out := make(chan *model.Post, f.cfg.Fetch.Buffer)
+	stats := model.NewFetchStats()
 	go func() {
 		var wg sync.WaitGroup
 
@@ -47,16 +55,21 @@ func (f *TDLibFetcher) RunFetchPipelene(ctx context.Context, from, to time.Time)
 			wg.Add(1)
 			go func(username string) {
 				defer wg.Done()
+				start := time.Now()
+				defer func() {
+					stats.FinishChannel(username, time.Since(start))
+				}()
 
 				chatID, err := f.FindChat(username)
 				if err != nil {
 					f.log.Error("Failed to find chat", "username", username, "err", err)
-					f.totalErrors++
+					stats.AddError(username)
 					return
 				}
 
-				resultCh, errCh := f.RunPipeline(ctx, chatID, from, to)
+				resultCh, errCh := f.RunPipeline(ctx, chatID, username, from, to, stats)
 				f.log.Info("Fetch pipeline started", "username", username)
+				tracker := newProgressTracker(f.progress, f.interval, username, from, to)
 
 				count := 0
 				for {
@@ -66,16 +79,18 @@ func (f *TDLibFetcher) RunFetchPipelene(ctx context.Context, from, to time.Time)
 						return
 					case post, ok := <-resultCh:
 						if !ok {
+							tracker.Done()
 							f.log.Info("Fetch workers completed", "username", username, "count", count)
 							return
 						}
 						post.Username = username
-						f.totalFetched++
+						stats.AddFetched(username)
+						tracker.Observe(post)
 						count++
 						out <- post
 					case err, ok := <-errCh:
 						if ok {
-							f.totalErrors++
+							stats.AddError(username)
 							f.log.Error("Pipeline error", "username", username, "err", err)
 						}
 					}
@@ -84,14 +99,16 @@ func (f *TDLibFetcher) RunFetchPipelene(ctx context.Context, from, to time.Time)
 			}(username)
 		}
 		wg.Wait()
+		stats.Finish()
 		close(out)
-		f.log.Info("All usernames processed", "total_fetched", f.totalFetched, "total_errors", f.totalErrors)
+		totals := stats.Totals()
+		f.log.Info("All usernames processed", "total_fetched", totals.Fetched, "total_filtered", totals.FilteredTotal(), "total_errors", totals.Errors)
 	}()
-	return out
+	return out, stats
 }
 
-func (f *TDLibFetcher) RunPipeline(ctx context.Context, chatID int64, from, to time.Time) (<-chan *model.Post, <-chan error) {
-	const numWorkers = 5
+func (f *TDLibFetcher) RunPipeline(ctx context.Context, chatID int64, username string, from, to time.Time, stats *model.FetchStats) (<-chan *model.Post, <-chan error) {
+	numWorkers := f.throttle.MaxWorkers()
 
 	rawOut := make(chan *client.Message)
 	postOut := make(chan *model.Post)
@@ -101,12 +118,39 @@ func (f *TDLibFetcher) RunPipeline(ctx context.Context, chatID int64, from, to t
 		defer close(rawOut)
 		defer close(errCh)
 		f.log.Info("Producer: GetHistoryByPeriod started", "from", from, "to", to)
-		err := f.GetHistoryByPeriod(ctx, chatID, from, to, rawOut)
+		err := f.GetHistoryByPeriod(ctx, chatID, from, to, rawOut, func(messages int, local bool) {
+			stats.AddPage(username, messages, local)
+		})
 		if err != nil {
 			errCh <- fmt.Errorf("GetHistory failed: %w", err)
 		}
 	}()
 
+	// Messages are validated and numbered in the order GetHistoryByPeriod
+	// sends them, before the workers finish them in any order, so that the
+	// analyzer can restore this order, see model.Post.Sequence.
+	validated := make(chan *model.Post)
+	go func() {
+		defer close(validated)
+		var seq uint64
+		for raw := range rawOut {
+			post, reason := f.ValidateMessage(raw)
+			if post == nil {
+				stats.AddFiltered(username, reason)
+				continue
+			}
+			seq++
+			post.Sequence = seq
+			select {
+			case <-ctx.Done():
+				for range rawOut {
+				}
+				return
+			case validated <- post:
+			}
+		}
+	}()
+
 	var wg sync.WaitGroup
 	wg.Add(numWorkers)
 
@@ -114,15 +158,14 @@ func (f *TDLibFetcher) RunPipeline(ctx context.Context, chatID int64, from, to t
 		go func(workerID int) {
 			defer wg.Done()
 			f.log.Debug("Worker started", "worker", workerID)
-			for raw := range rawOut {
-				post, ok := f.ValidateMessage(raw)
-				if !ok {
-					f.totalFiltered++
-					continue
+			for post := range validated {
+				link, err := f.getMessageLink(ctx, chatID, post.ID)
+				if ctx.Err() != nil {
+					f.log.Warn("Context canceled in worker", "worker", workerID)
+					return
 				}
-				link, err := f.getMessageLink(chatID, post.ID)
 				if err != nil {
-					f.totalErrors++
+					stats.AddLinkFailure(username)
 					f.log.Error("Failed to get message link", "id", post.ID, "err", err)
 				}
 				post.Link = link
@@ -157,66 +200,90 @@ func (f *TDLibFetcher) FindChat(username string) (int64, error) {
 	return chat.Id, nil
 }
 
-func (f *TDLibFetcher) GetHistoryByPeriod(ctx context.Context, chatID int64, from, to time.Time, out chan<- *client.Message) error {
-	var fromMessageID int64
-	stop := false
+// GetHistoryByPeriod sends messages of the chat published between from and to
+// into out, newest first. Unless from_message_id is configured, it first jumps
+// to the last message sent no later than to, so only the requested window is
+// paged. With local_first the window is served from the TDLib message database
+// and only the missing ranges are requested from the network. onPage, if not
+// nil, is called with the size of every page read.
+func (f *TDLibFetcher) GetHistoryByPeriod(ctx context.Context, chatID int64, from, to time.Time, out chan<- *client.Message, onPage func(messages int, local bool)) error {
+	start := historyPage{
+		chatID:        chatID,
+		fromMessageID: f.cfg.GetHistory.FromMessageID,
+		offset:        f.cfg.GetHistory.Offset,
+		onlyLocal:     f.cfg.GetHistory.OnlyLocal,
+	}
 
-	for {
-		select {
-		case <-ctx.Done():
-			f.log.Warn("Context cancelled in GetHistoryByPeriod")
+	if start.fromMessageID == 0 {
+		anchorID, found, err := f.seekMessageByDate(ctx, chatID, to)
+		switch {
+		case err != nil:
+			f.log.Warn("Failed to seek message by date, paging from the newest message", "chat_id", chatID, "err", err)
+		case !found:
+			f.log.Info("No messages before the end of period", "chat_id", chatID, "to", to)
 			return nil
 		default:
+			// offset -1 makes the anchor itself part of the first page
+			start.fromMessageID = anchorID
+			start.offset = -1
 		}
+	}
 
-		history, err := f.client.GetChatHistory(&client.GetChatHistoryRequest{
-			ChatId:        chatID,
-			FromMessageId: fromMessageID,
-			Offset:        0,
-			Limit:         50,
-			OnlyLocal:     false,
-		})
-		if err != nil {
-			f.totalErrors++
-			f.log.Error("GetChatHistory failed", "chat_id", chatID, "err", err)
-			return err
-		}
-		if len(history.Messages) == 0 || stop {
-			f.log.Info("Reached end of history", "chat_id", chatID)
-			return nil
+	send := func(msg *client.Message) bool {
+		select {
+		case <-ctx.Done():
+			f.log.Warn("Context cancelled while sending message")
+			return false
+		case out <- msg:
+			return true
 		}
+	}
 
-		for _, msg := range history.Messages {
-			t := time.Unix(int64(msg.Date), 0)
-			if t.After(to) {
-				continue
-			}
-			if t.Before(from) {
-				stop = true
-				break
-			}
-			select {
-			case <-ctx.Done():
-				f.log.Warn("Context cancelled while sending message")
-				return nil
-			case out <- msg:
-			}
-		}
+	if f.cfg.UseMessageDatabase && f.cfg.Fetch.LocalFirst && !start.onlyLocal {
+		return f.getHistoryLocalFirst(ctx, start, from, to, send, onPage)
+	}
+
+	_, err := f.pageHistory(ctx, start, from, to, send, onPage)
+	return err
+}
 
-		fromMessageID = history.Messages[len(history.Messages)-1].Id
+// seekMessageByDate returns the identifier of the last message sent no later
+// than date. found is false if the chat has no such message.
+func (f *TDLibFetcher) seekMessageByDate(ctx context.Context, chatID int64, date time.Time) (int64, bool, error) {
+	msg, err := withFloodRetry(ctx, f.throttle, f.log, func() (*client.Message, error) {
+		return f.client.GetChatMessageByDate(&client.GetChatMessageByDateRequest{
+			ChatId: chatID,
+			Date:   int32(date.Unix()),
+		})
+	})
+	if err != nil {
+		var respErr client.ResponseError
+		if errors.As(err, &respErr) && respErr.Err != nil && respErr.Err.Code == 404 {
+			return 0, false, nil
+		}
+		return 0, false, fmt.Errorf("GetChatMessageByDate error: %w", err)
+	}
+	if msg == nil {
+		return 0, false, nil
 	}
+	f.log.Debug("Seeked message by date", "chat_id", chatID, "date", date, "message_id", msg.Id)
+	return msg.Id, true, nil
 }
 
-func (f *TDLibFetcher) getMessageLink(chatID int64, messageID int64) (string, error) {
+func (f *TDLibFetcher) getMessageLink(ctx context.Context, chatID int64, messageID int64) (string, error) {
 	req := &client.GetMessageLinkRequest{ChatId: chatID, MessageId: messageID}
-	resp, err := f.client.GetMessageLink(req)
+	resp, err := withWorkerSlot(ctx, f.throttle, f.log, func() (*client.MessageLink, error) {
+		return f.client.GetMessageLink(req)
+	})
 	if err != nil {
 		return "", fmt.Errorf("GetMessageLink error: %w", err)
 	}
 	return resp.Link, nil
 }
 
-func (f *TDLibFetcher) ValidateMessage(raw *client.Message) (*model.Post, bool) {
+// ValidateMessage converts a raw message into a post. If the message is
+// filtered out, the post is nil and the reason is returned instead.
+func (f *TDLibFetcher) ValidateMessage(raw *client.Message) (*model.Post, string) {
 	var text string
 	switch content := raw.Content.(type) {
 	case *client.MessageText:
@@ -226,20 +293,18 @@ func (f *TDLibFetcher) ValidateMessage(raw *client.Message) (*model.Post, bool)
 	case *client.MessageVideo:
 		text = content.Caption.Text
 	default:
-		f.totalFiltered++
 		f.log.Warn("Unsupported message content", "type", fmt.Sprintf("%T", raw.Content))
-		return nil, false
+		return nil, model.FilterUnsupportedContent
 	}
 
 	text = strings.TrimSpace(text)
 	if text == "" {
-		f.totalFiltered++
-		return nil, false
+		return nil, model.FilterEmptyText
 	}
 
 	return &model.Post{
 		ID:        raw.Id,
 		Text:      text,
 		Timestamp: time.Unix(int64(raw.Date), 0),
-	}, true
+	}, ""
 }
@@ -53,7 +53,7 @@ func TestFetch(t *testing.T) {
 	config.TDLib.FilesDirectory = tdlibFilesPath
 
 	tdlibclient, _ := fetcher.NewClient(config.TDLib)
-	tdlibFetcher, err := fetcher.NewTDLibFetcher(tdlibclient, zaplogger, config.TDLib)
+	tdlibFetcher, err := fetcher.NewTDLibFetcher(tdlibclient, zaplogger, config.TDLib, nil, config.Progress.Interval)
 	if err != nil {
 		zaplogger.Error("tdlibfetcher error", err)
 	}
@@ -135,7 +135,7 @@ func checkMessages(periodAndCountMessages map[int]Period, chatName []string, f *
 	repeats := make(map[string]struct{})
 	for exceptedCount, period := range periodAndCountMessages {
 		counter := 0
-		out := f.RunFetchPipelene(ctx, period.from, period.to)
+		out, _ := f.RunFetchPipelene(ctx, period.from, period.to)
 
 		for msg := range out {
 			if _, exists := repeats[msg.Text]; exists {
@@ -2,34 +2,66 @@ package analyzer
 
 import (
 	"context"
-	"strings"
+	"runtime"
+	"sync/atomic"
 	"time"
 
 	"github.com/ScrpTrx-Go/GoTGParse/internal/domain/model"
 	pkg "github.com/ScrpTrx-Go/GoTGParse/pkg/logger"
-	"github.com/cloudflare/ahocorasick"
 )
 
 type AnalyzeWorker struct {
-	matchers Matchers
-	log      pkg.Logger
-	regions  []string
-	dict     Dictionaries
-	count    int
-	skipped  int
+	id         int
+	engine     atomic.Pointer[Engine]
+	log        pkg.Logger
+	classifier *ClassifierPolicy
+	// specialType is the type confidence from which an errand is of the
+	// special type, config.Confidence.SpecialType.
+	specialType float64
 }
 
-func NewAnalyzeWorker(factory MatchersCreator, log pkg.Logger, regions []string, dict Dictionaries) AnalyzePostWorker {
-	return &AnalyzeWorker{
-		matchers: factory.CreateMatchers(),
-		log:      log,
-		regions:  regions,
-		dict:     dict,
+// NewAnalyzeWorker creates a worker; classifier may be nil to rely on the
+// dictionaries alone. specialType is the type confidence from which
+// Post.ErrandType is set, the same threshold the report applies.
+func NewAnalyzeWorker(id int, engine *Engine, log pkg.Logger, classifier *ClassifierPolicy, specialType float64) AnalyzePostWorker {
+	worker := &AnalyzeWorker{
+		id:          id,
+		log:         log,
+		classifier:  classifier,
+		specialType: specialType,
 	}
+	worker.engine.Store(engine)
+	return worker
 }
 
-func (a *AnalyzeWorker) Run(ctx context.Context, in <-chan *model.Post, out chan<- *model.Post) {
+// NewAnalyzeWorkers creates count workers sharing one engine. A count of zero
+// or less creates one worker per GOMAXPROCS.
+func NewAnalyzeWorkers(count int, log pkg.Logger, dict *Dictionaries, rules ChannelRules, normalizer Normalizer, classifier *ClassifierPolicy, specialType float64) []AnalyzePostWorker {
+	if count <= 0 {
+		count = runtime.GOMAXPROCS(0)
+	}
+	regions := GetRegionKeys(dict.RegionsAllias)
+	engine := NewEngine(NewMatcherCreator(dict, regions, normalizer), regions, *dict, rules)
+	workers := make([]AnalyzePostWorker, 0, count)
+	for i := 0; i < count; i++ {
+		workers = append(workers, NewAnalyzeWorker(i, engine, log, classifier, specialType))
+	}
+	return workers
+}
+
+// Reload replaces the engine used for the following posts. A post that is
+// being analyzed keeps the engine it was started with.
+func (a *AnalyzeWorker) Reload(engine *Engine) {
+	a.engine.Store(engine)
+}
+
+func (a *AnalyzeWorker) Run(ctx context.Context, in <-chan *model.Post, out chan<- *model.Post, stats *model.AnalysisStats) {
 	start := time.Now()
+	counters := model.NewWorkerStats(a.id)
+	defer func() {
+		counters.Duration = time.Since(start)
+		stats.AddWorker(counters)
+	}()
 	for post := range in {
 		select {
 		case <-ctx.Done():
@@ -38,17 +70,23 @@ func (a *AnalyzeWorker) Run(ctx context.Context, in <-chan *model.Post, out chan
 		default:
 		}
 
-		if !a.IsErrand(post) {
-			a.skipped++
-			continue
+		engine := a.engine.Load()
+		post.Trace = &model.AnalysisTrace{}
+		post.DictionaryVersion = engine.Version()
+		post.ErrandConfidence = engine.ErrandConfidence(post)
+		if a.classifier != nil {
+			post.ErrandConfidence = a.classifier.Confidence(post, post.ErrandConfidence)
+		}
+		post.IsErrand = post.ErrandConfidence > 0
+		counters.Add(post.Username, post.IsErrand)
+		if post.IsErrand {
+			place := engine.ExtractRegions(post)
+			post.Regions, post.RegionConfidence, post.Localities = place.Regions, place.Confidence, place.Localities
+			post.SecondaryRegions = place.Secondary
+			post.TypeConfidence = engine.TypeConfidence(post)
+			post.ErrandType = post.TypeConfidence >= a.specialType
+			post.Categories = engine.Categories(post)
 		}
-
-		a.count++
-		postRegions := a.ExtractRegions(post)
-		post.Regions = postRegions
-
-		errandType := a.ErrandType(post)
-		post.ErrandType = errandType
 
 		select {
 		case <-ctx.Done():
@@ -57,123 +95,5 @@ func (a *AnalyzeWorker) Run(ctx context.Context, in <-chan *model.Post, out chan
 		case out <- post:
 		}
 	}
-	duration := time.Since(start)
-	a.log.Info("AnalyzeWorker completed", "matched", a.count, "skipped", a.skipped, "duration", duration.String())
-}
-
-func (a *AnalyzeWorker) IsErrand(post *model.Post) bool {
-	switch post.Username {
-	case "sledcom_press":
-		if a.CheckErrandTitle(post) {
-			return true
-		}
-	case "infocentrskrf":
-		if a.TitleHasPrefix(post) {
-			return true
-		}
-	}
-	return false
-}
-
-func (a *AnalyzeWorker) TitleHasPrefix(post *model.Post) bool {
-	matches := a.matchers.PrefixICMatcher.Match([]byte(post.Text))
-	return len(matches) > 0
-}
-
-func (a *AnalyzeWorker) CheckErrandTitle(post *model.Post) bool {
-	errandMatchers := []*ahocorasick.Matcher{a.matchers.PrefixMatcher, a.matchers.VerbMatcher, a.matchers.PSKMatcher}
-	errandTitle := a.GetLowTitle(post.Text)
-	matchesCounter := 0
-	for _, errandMatch := range errandMatchers {
-		matches := errandMatch.Match([]byte(errandTitle))
-		if len(matches) > 0 {
-			matchesCounter++
-		}
-	}
-
-	if matchesCounter == 2 {
-		post.ErrorType = "maybe errand"
-		return true
-	}
-	return matchesCounter == 3
-}
-
-func (a *AnalyzeWorker) GetLowTitle(text string) string {
-	split := strings.SplitN(text, "\n", 2)
-	title := strings.ToLower(strings.Join(strings.Fields(split[0]), " "))
-	return title
-}
-
-func (a *AnalyzeWorker) ExtractRegions(post *model.Post) []string {
-	text := a.FindErrandBody(post)
-	matches := a.matchers.Regions.Match([]byte(text))
-
-	if len(matches) == 0 {
-		loweredText := strings.ToLower(post.Text)
-		matchesFull := a.matchers.Regions.Match([]byte(loweredText))
-		errandRegions := a.FoundRegionsName(matchesFull)
-		result := a.CheckException(errandRegions)
-		if len(matchesFull) > 1 {
-			return nil
-		}
-		return result
-	}
-	errandRegions := a.FoundRegionsName(matches)
-	result := a.CheckException(errandRegions)
-	return result
-}
-
-func (a *AnalyzeWorker) CheckException(errandRegions []string) []string {
-	for _, region := range errandRegions {
-		for _, exception := range a.dict.ExceptionsDictonary {
-			if region == exception {
-				errandRegions = []string{exception}
-				break
-			}
-		}
-	}
-	return errandRegions
-}
-
-func (a *AnalyzeWorker) FindErrandBody(post *model.Post) string {
-	loweredText := strings.ToLower(post.Text)
-	paragraphs := strings.Split(loweredText, "\n")
-	var maxLen int
-	var errandBody string
-	for idx, para := range paragraphs {
-		if idx == 0 {
-			continue
-		}
-		currentLen := len(a.matchers.ErrandBodyMatcher.Match([]byte(para)))
-		if currentLen > maxLen {
-			maxLen = currentLen
-			errandBody = para
-		}
-	}
-	normalizedErrandBody := strings.TrimSpace(errandBody)
-	if normalizedErrandBody == "" {
-		normalizedErrandBody = strings.ToLower(strings.TrimSpace(post.Text))
-	}
-	return normalizedErrandBody
-}
-
-func (a *AnalyzeWorker) FoundRegionsName(matches []int) []string {
-	seen := make(map[string]struct{})
-	errandRegions := make([]string, 0)
-
-	for _, match := range matches {
-		if region, ok := a.dict.RegionsAllias[a.regions[match]]; ok {
-			if _, ok := seen[region]; !ok {
-				seen[region] = struct{}{}
-				errandRegions = append(errandRegions, region)
-			}
-		}
-	}
-	return errandRegions
-}
-
-func (a *AnalyzeWorker) ErrandType(post *model.Post) bool {
-	text := a.FindErrandBody(post)
-	matches := a.matchers.ErrandType.Match([]byte(text))
-	return len(matches) > 0
+	a.log.Debug("AnalyzeWorker completed", "worker", a.id, "matched", counters.Errands, "skipped", counters.Skipped)
 }
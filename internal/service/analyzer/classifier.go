@@ -0,0 +1,62 @@
+package analyzer
+
+import (
+	"fmt"
+
+	"github.com/ScrpTrx-Go/GoTGParse/internal/domain/model"
+)
+
+// Modes of the statistical classifier.
+const (
+	ClassifierAssist  = "assist"
+	ClassifierReplace = "replace"
+)
+
+// RuleClassifier is the matched rule of the posts the classifier decides.
+const RuleClassifier = "classifier"
+
+// ClassifierPolicy decides how a classifier takes part in errand detection.
+// In assist mode it only decides posts the dictionaries reject, and makes
+// them errands with confidenceMaybe so that they go to the review queue. In
+// replace mode its probability is the errand confidence.
+type ClassifierPolicy struct {
+	Classifier Classifier
+	Mode       string
+	Threshold  float64
+}
+
+func NewClassifierPolicy(classifier Classifier, mode string, threshold float64) (*ClassifierPolicy, error) {
+	if mode != ClassifierAssist && mode != ClassifierReplace {
+		return nil, fmt.Errorf("unknown classifier mode %q", mode)
+	}
+	return &ClassifierPolicy{
+		Classifier: classifier,
+		Mode:       mode,
+		Threshold:  threshold,
+	}, nil
+}
+
+// Confidence returns the errand confidence of the post given the confidence
+// of the dictionaries. The matched rule of the post names the classifier
+// whenever its result is returned.
+func (p *ClassifierPolicy) Confidence(post *model.Post, dictionary float64) float64 {
+	if p.Mode == ClassifierAssist && dictionary > 0 {
+		return dictionary
+	}
+	probability := p.Classifier.Probability(post.Text)
+	if post.Trace != nil {
+		post.Trace.Classifier = &probability
+	}
+	if p.Mode == ClassifierReplace {
+		post.MatchedRule = RuleClassifier
+		if probability < p.Threshold {
+			return confidenceUnknown
+		}
+		return probability
+	}
+	if probability < p.Threshold {
+		return dictionary
+	}
+	post.MatchedRule = RuleClassifier
+	return confidenceMaybe
+}
@@ -1,9 +1,12 @@
 package analyzer
 
+import "github.com/ScrpTrx-Go/GoTGParse/internal/domain/model"
+
 type DefaultDictionariesCreator struct {
 }
 
 type Dictionaries struct {
+	Version               string
 	PrefixDictionary      []string
 	PrefixDictionaryIC    []string
 	VerbsDictionary       []string
@@ -12,6 +15,18 @@ type Dictionaries struct {
 	RegionsAllias         map[string]string
 	ExceptionsDictonary   []string
 	ErrandTypesDictionary []string
+	Localities            map[string]model.Locality
+	Categories            []Category
+	// PlaceExclusions are phrases that contain a region or locality term but
+	// name no place of an errand, e.g. railway stations.
+	PlaceExclusions []string
+}
+
+// Category is a topic of errands, e.g. children's rights or housing, with the
+// terms that assign it. A post can belong to several categories.
+type Category struct {
+	Name  string   `yaml:"name"`
+	Terms []string `yaml:"terms"`
 }
 
 func NewDictionariesCreator() DictionariesCreator {
@@ -20,6 +35,7 @@ func NewDictionariesCreator() DictionariesCreator {
 
 func (c *DefaultDictionariesCreator) CreateDictionaries() *Dictionaries {
 	return &Dictionaries{
+		Version:               EmbeddedDictionariesVersion,
 		PrefixDictionary:      prefix,
 		PrefixDictionaryIC:    prefixIC,
 		VerbsDictionary:       verbs,
@@ -28,5 +44,8 @@ func (c *DefaultDictionariesCreator) CreateDictionaries() *Dictionaries {
 		RegionsAllias:         regionsMap,
 		ExceptionsDictonary:   exceptions,
 		ErrandTypesDictionary: types,
+		Localities:            localities,
+		Categories:            categories,
+		PlaceExclusions:       placeExclusions,
 	}
 }
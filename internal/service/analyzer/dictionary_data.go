@@ -1,15 +1,17 @@
 package analyzer
 
+import "github.com/ScrpTrx-Go/GoTGParse/internal/domain/model"
+
 var verbs = []string{
-	"поруч",
-	"долож",
-	"запрос",
-	"затреб",
-	"представ",
-	"доклад",
-	"контроль",
-	"треб",
-	"возбу",
+	"поруч*",
+	"долож*",
+	"запрос*",
+	"затреб*",
+	"представ*",
+	"доклад*",
+	"контроль*",
+	"треб*",
+	"возбу*",
 }
 
 var prefix = []string{
@@ -24,11 +26,11 @@ var prefix = []string{
 }
 
 var psk = []string{
-	"глав",
-	"председ",
+	"глав*",
+	"председ*",
 	"бастрыкин",
-	"аппарат",
-	"руковод",
+	"аппарат*",
+	"руковод*",
 }
 
 var prefixIC = []string{
@@ -36,18 +38,19 @@ var prefixIC = []string{
 	"🟥🟥🟥🟥",
 }
 
+// errandBody scores the paragraphs of a post by the number of matched
+// entries.
 var errandBody = []string{
-	"руковод",
-	"област",
-	"республик",
+	"руковод*",
+	"област*",
+	"республик*",
 	"краю",
-	"округ",
-	"поруч",
-	"глав",
-	"председ",
-	"бастрыкин",
-	"аппарат",
+	"округ*",
+	"поруч*",
+	"глав*",
+	"председ*",
 	"бастрыкин",
+	"аппарат*",
 }
 
 var regionsMap = map[string]string{
@@ -60,28 +63,28 @@ var regionsMap = map[string]string{
 	" волгоградск":     "Волгоградская область",
 	" вологодск":       "Вологодская область",
 	" воронежск":       "Воронежская область",
-	" вмсут":           "Восточный МСУТ",
+	" вмсут*":          "Восточный МСУТ",
 	" восточного мсут": "Восточный МСУТ",
 	" восточного межрегионального": "Восточный МСУТ",
-	"москв":      "Москва",
+	"москв*":     "Москва",
 	"столичного": "Москва",
-	"петербург":  "Санкт-Петербург",
+	"петербург*": "Санкт-Петербург",
 	" гвсу":      "ГВСУ",
 	" главного военного следственного управления": "ГВСУ",
-	" забайкальск":    "Забайкальский край",
-	" змсут":          "Западный МСУТ",
-	" западного мсут": "Западный МСУТ",
+	" забайкальск":                "Забайкальский край",
+	" змсут*":                     "Западный МСУТ",
+	" западного мсут":             "Западный МСУТ",
 	" западного межрегионального": "Западный МСУТ",
 	" ивановской":                 "Ивановская область",
 	" иркутск":                    "Иркутская область",
-	" кабардино":                  "Кабардино-Балкария",
+	" кабардино*":                 "Кабардино-Балкария",
 	" калининградск":              "Калининградская область",
 	" калужск":                    "Калужская область",
 	" камчатск":                   "Камчатский край",
-	" карачаево":                  "Карачаево-Черкесия",
+	" карачаево*":                 "Карачаево-Черкесия",
 	" кемеровск":                  "Кемеровская область",
 	" кировск":                    "Кировская область",
-	" байконур":                   "Байконур",
+	" байконур*":                  "Байконур",
 	" костромск":                  "Костромская область",
 	" краснодарск":                "Краснодарский край",
 	" красноярск":                 "Красноярский край",
@@ -102,23 +105,23 @@ var regionsMap = map[string]string{
 	" пермск":                     "Пермский край",
 	" приморск":                   "Приморский край",
 	" псковск":                    "Псковская область",
-	" адыге":                      "Адыгея",
+	" адыге*":                     "Адыгея",
 	" республике алтай":           "Республика Алтай",
 	" алтайскому краю":            "Алтайский край",
-	" башкортостан":               "Башкортостан",
-	" буряти":                     "Бурятия",
-	" дагестан":                   "Дагестан",
-	" ингушети":                   "Ингушетия",
-	" калмыки":                    "Калмыкия",
-	" карели":                     "Карелия",
+	" башкортостан*":              "Башкортостан",
+	" буряти*":                    "Бурятия",
+	" дагестан*":                  "Дагестан",
+	" ингушети*":                  "Ингушетия",
+	" калмыки*":                   "Калмыкия",
+	" карели*":                    "Карелия",
 	" республике коми":            "Республика Коми",
 	" республике крым":            "Республика Крым",
-	" марий":                      "Марий Эл",
-	" мордови":                    "Мордовия",
-	"якути":                       "Якутия",
-	" осети":                      "Северная Осетия — Алания",
-	" татарстан":                  "Татарстан",
-	" тыв":                        "Тыва",
+	" марий*":                     "Марий Эл",
+	" мордови*":                   "Мордовия",
+	"якути*":                      "Якутия",
+	" осети*":                     "Северная Осетия — Алания",
+	" татарстан*":                 "Татарстан",
+	" тыв*":                       "Тыва",
 	" ростовск":                   "Ростовская область",
 	" рязанск":                    "Рязанская область",
 	" самарск":                    "Самарская область",
@@ -132,12 +135,12 @@ var regionsMap = map[string]string{
 	" томск":                      "Томская область",
 	" тульск":                     "Тульская область",
 	" тюменск":                    "Тюменская область",
-	" удмурт":                     "Удмуртия",
+	" удмурт*":                    "Удмуртия",
 	" ульяновск":                  "Ульяновская область",
 	" хабаровск":                  "Хабаровский край",
-	" ханты":                      "Ханты-Мансийский АО — Югра",
+	" ханты*":                     "Ханты-Мансийский АО — Югра",
 	" хмао":                       "Ханты-Мансийский АО — Югра",
-	" цмсут":                      "Центральный МСУТ",
+	" цмсут*":                     "Центральный МСУТ",
 	" центрального мсут":          "Центральный МСУТ",
 	" центрального межрегионального":                                   "Центральный МСУТ",
 	" следователям главного следственного управления":                  "Центральный аппарат СК РФ",
@@ -148,9 +151,9 @@ var regionsMap = map[string]string{
 	" руководству главного следственного управления ск россии":         "Центральный аппарат СК РФ",
 	" челябинск": "Челябинская область",
 	" чеченск":   "Чеченская республика",
-	" чуваш":     "Чувашия",
+	" чуваш*":    "Чувашия",
 	" чукотск":   "Чукотский автономный округ",
-	" ямал":      "Ямало-Ненецкий АО",
+	" ямал*":     "Ямало-Ненецкий АО",
 	" янао":      "Ямало-Ненецкий АО",
 	" ярославск": "Ярославская область",
 	" луганск":   "Луганская Народная Республика",
@@ -165,9 +168,192 @@ var exceptions = []string{
 	"ГВСУ",
 }
 
+// placeExclusions are phrases such as names of railway stations that contain
+// the name of a region or locality but say nothing about where an errand is.
+// Every form is listed, as without morphology they match as plain substrings.
+var placeExclusions = []string{
+	"курский вокзал",
+	"курского вокзала",
+	"курскому вокзалу",
+	"курским вокзалом",
+	"курском вокзале",
+	"ленинградский вокзал",
+	"ленинградского вокзала",
+	"ленинградскому вокзалу",
+	"ленинградским вокзалом",
+	"ленинградском вокзале",
+	"ярославский вокзал",
+	"ярославского вокзала",
+	"ярославскому вокзалу",
+	"ярославским вокзалом",
+	"ярославском вокзале",
+	"московский вокзал",
+	"московского вокзала",
+	"московскому вокзалу",
+	"московским вокзалом",
+	"московском вокзале",
+	"братская могила",
+	"братской могилы",
+	"братской могиле",
+	"братскую могилу",
+	"братские могилы",
+	"братских могил",
+	"братское захоронение",
+	"братского захоронения",
+	"братском захоронении",
+	"братские захоронения",
+	"братских захоронений",
+}
+
 // срез для ErrandType
 var types = []string{
 	"провести",
 	"организовать",
 	"возбудить",
 }
+
+// categories is the default errand taxonomy, in the order reports list it.
+var categories = []Category{
+	{Name: "Права детей", Terms: []string{"ребен*", "ребён*", "детей", "детьми", "детям", "несовершеннолетн*", "школьни*", "воспитанни*", "сирот*"}},
+	{Name: "ЖКХ", Terms: []string{"жкх", "коммунальн*", "аварийный дом", "аварийного дома", "аварийном доме", "аварийные дома", "аварийных домов", "аварийного жилья", "аварийное жильё", "аварийном жилье", "аварийном состоянии", "управляющей компании", "отоплени*", "водоснабжени*", "канализаци*", "капремонт*", "жилищн*"}},
+	{Name: "Мигранты", Terms: []string{"мигрант*", "иностранц*", "иностранного гражданина", "иностранными гражданами", "приезжие", "приезжих", "приезжим", "приезжими"}},
+	{Name: "Здравоохранение", Terms: []string{"больниц*", "медицинской помощи", "медицинскую помощь", "медицинская помощь", "медицинского учреждения", "медицинском учреждении", "медицинских учреждений", "медицинской организации", "медицинских работников", "медработник*", "медучреждени*", "врач*", "поликлиник*", "пациент*", "скорой помощи", "медпомощ*"}},
+	{Name: "Ветераны", Terms: []string{"ветеран*", "участник сво", "участника сво", "участнику сво", "специальной военной операции"}},
+}
+
+// localities maps cities and districts to their federal subject. Keys are
+// word forms rather than stems where a stem would also match the name of the
+// subject, e.g. "самаре" but not "самар*", which would match "самарской". A
+// form that starts other words is closed by a space or punctuation, e.g.
+// "сочи " so that "сочинение" does not match.
+var localities = map[string]model.Locality{
+	" екатеринбург*":         {Name: "Екатеринбург", Region: "Свердловская область"},
+	" тагил*":                {Name: "Нижний Тагил", Region: "Свердловская область"},
+	" каменск-уральск":       {Name: "Каменск-Уральский", Region: "Свердловская область"},
+	" первоуральск":          {Name: "Первоуральск", Region: "Свердловская область"},
+	" люберц*":               {Name: "Люберцы", Region: "Московская область"},
+	" подольск":              {Name: "Подольск", Region: "Московская область"},
+	" балаших*":              {Name: "Балашиха", Region: "Московская область"},
+	" химках":                {Name: "Химки", Region: "Московская область"},
+	" химки":                 {Name: "Химки", Region: "Московская область"},
+	" мытищ*":                {Name: "Мытищи", Region: "Московская область"},
+	" красногорск":           {Name: "Красногорск", Region: "Московская область"},
+	" одинцово":              {Name: "Одинцово", Region: "Московская область"},
+	" одинцовск":             {Name: "Одинцово", Region: "Московская область"},
+	" серпухов*":             {Name: "Серпухов", Region: "Московская область"},
+	" коломн*":               {Name: "Коломна", Region: "Московская область"},
+	" электростал*":          {Name: "Электросталь", Region: "Московская область"},
+	" ногинск":               {Name: "Ногинск", Region: "Московская область"},
+	" домодедов*":            {Name: "Домодедово", Region: "Московская область"},
+	" раменск":               {Name: "Раменское", Region: "Московская область"},
+	" щёлков*":               {Name: "Щёлково", Region: "Московская область"},
+	" щелков*":               {Name: "Щёлково", Region: "Московская область"},
+	" сергиевом посаде":      {Name: "Сергиев Посад", Region: "Московская область"},
+	" сергиев посад":         {Name: "Сергиев Посад", Region: "Московская область"},
+	" нижнем новгороде":      {Name: "Нижний Новгород", Region: "Нижегородская область"},
+	" нижний новгород":       {Name: "Нижний Новгород", Region: "Нижегородская область"},
+	" нижнего новгорода":     {Name: "Нижний Новгород", Region: "Нижегородская область"},
+	" дзержинске":            {Name: "Дзержинск", Region: "Нижегородская область"},
+	" арзамас*":              {Name: "Арзамас", Region: "Нижегородская область"},
+	" казани":                {Name: "Казань", Region: "Татарстан"},
+	" казань":                {Name: "Казань", Region: "Татарстан"},
+	" набережных челнах":     {Name: "Набережные Челны", Region: "Татарстан"},
+	" набережные челны":      {Name: "Набережные Челны", Region: "Татарстан"},
+	" альметьевск":           {Name: "Альметьевск", Region: "Татарстан"},
+	" нижнекамск":            {Name: "Нижнекамск", Region: "Татарстан"},
+	" уфе":                   {Name: "Уфа", Region: "Башкортостан"},
+	" уфы":                   {Name: "Уфа", Region: "Башкортостан"},
+	" стерлитамак*":          {Name: "Стерлитамак", Region: "Башкортостан"},
+	" самаре":                {Name: "Самара", Region: "Самарская область"},
+	" самары":                {Name: "Самара", Region: "Самарская область"},
+	" самара":                {Name: "Самара", Region: "Самарская область"},
+	" тольятти":              {Name: "Тольятти", Region: "Самарская область"},
+	" сызран*":               {Name: "Сызрань", Region: "Самарская область"},
+	" ростове-на-дону":       {Name: "Ростов-на-Дону", Region: "Ростовская область"},
+	" ростова-на-дону":       {Name: "Ростов-на-Дону", Region: "Ростовская область"},
+	" ростов-на-дону":        {Name: "Ростов-на-Дону", Region: "Ростовская область"},
+	" таганрог*":             {Name: "Таганрог", Region: "Ростовская область"},
+	" новочеркасск":          {Name: "Новочеркасск", Region: "Ростовская область"},
+	" волгодонск":            {Name: "Волгодонск", Region: "Ростовская область"},
+	" краснодаре":            {Name: "Краснодар", Region: "Краснодарский край"},
+	" краснодара":            {Name: "Краснодар", Region: "Краснодарский край"},
+	" сочи ":                 {Name: "Сочи", Region: "Краснодарский край"},
+	" сочи,":                 {Name: "Сочи", Region: "Краснодарский край"},
+	" сочи.":                 {Name: "Сочи", Region: "Краснодарский край"},
+	" новороссийск":          {Name: "Новороссийск", Region: "Краснодарский край"},
+	" армавир*":              {Name: "Армавир", Region: "Краснодарский край"},
+	" геленджик*":            {Name: "Геленджик", Region: "Краснодарский край"},
+	" анапе":                 {Name: "Анапа", Region: "Краснодарский край"},
+	" анапы":                 {Name: "Анапа", Region: "Краснодарский край"},
+	" магнитогорск":          {Name: "Магнитогорск", Region: "Челябинская область"},
+	" златоуст*":             {Name: "Златоуст", Region: "Челябинская область"},
+	" миасс*":                {Name: "Миасс", Region: "Челябинская область"},
+	" омске":                 {Name: "Омск", Region: "Омская область"},
+	" омска":                 {Name: "Омск", Region: "Омская область"},
+	" перми":                 {Name: "Пермь", Region: "Пермский край"},
+	" пермь":                 {Name: "Пермь", Region: "Пермский край"},
+	" березники":             {Name: "Березники", Region: "Пермский край"},
+	" волгограде":            {Name: "Волгоград", Region: "Волгоградская область"},
+	" волгограда":            {Name: "Волгоград", Region: "Волгоградская область"},
+	" волжском":              {Name: "Волжский", Region: "Волгоградская область"},
+	" воронеже":              {Name: "Воронеж", Region: "Воронежская область"},
+	" воронежа":              {Name: "Воронеж", Region: "Воронежская область"},
+	" саратове":              {Name: "Саратов", Region: "Саратовская область"},
+	" саратова":              {Name: "Саратов", Region: "Саратовская область"},
+	" энгельсе":              {Name: "Энгельс", Region: "Саратовская область"},
+	" норильск":              {Name: "Норильск", Region: "Красноярский край"},
+	" ачинск":                {Name: "Ачинск", Region: "Красноярский край"},
+	" братске":               {Name: "Братск", Region: "Иркутская область"},
+	" братска ":              {Name: "Братск", Region: "Иркутская область"},
+	" братска,":              {Name: "Братск", Region: "Иркутская область"},
+	" братска.":              {Name: "Братск", Region: "Иркутская область"},
+	" братск ":               {Name: "Братск", Region: "Иркутская область"},
+	" братск,":               {Name: "Братск", Region: "Иркутская область"},
+	" братск.":               {Name: "Братск", Region: "Иркутская область"},
+	" ангарск":               {Name: "Ангарск", Region: "Иркутская область"},
+	" кемерово":              {Name: "Кемерово", Region: "Кемеровская область"},
+	" новокузнецк":           {Name: "Новокузнецк", Region: "Кемеровская область"},
+	" прокопьевск":           {Name: "Прокопьевск", Region: "Кемеровская область"},
+	" комсомольске-на-амуре": {Name: "Комсомольск-на-Амуре", Region: "Хабаровский край"},
+	" комсомольска-на-амуре": {Name: "Комсомольск-на-Амуре", Region: "Хабаровский край"},
+	" владивосток*":          {Name: "Владивосток", Region: "Приморский край"},
+	" уссурийск":             {Name: "Уссурийск", Region: "Приморский край"},
+	" тюмени":                {Name: "Тюмень", Region: "Тюменская область"},
+	" тюмень":                {Name: "Тюмень", Region: "Тюменская область"},
+	" тобольск":              {Name: "Тобольск", Region: "Тюменская область"},
+	" сургут*":               {Name: "Сургут", Region: "Ханты-Мансийский АО — Югра"},
+	" нижневартовск":         {Name: "Нижневартовск", Region: "Ханты-Мансийский АО — Югра"},
+	" нефтеюганск":           {Name: "Нефтеюганск", Region: "Ханты-Мансийский АО — Югра"},
+	" новом уренгое":         {Name: "Новый Уренгой", Region: "Ямало-Ненецкий АО"},
+	" новый уренгой":         {Name: "Новый Уренгой", Region: "Ямало-Ненецкий АО"},
+	" ноябрьск":              {Name: "Ноябрьск", Region: "Ямало-Ненецкий АО"},
+	" череповц*":             {Name: "Череповец", Region: "Вологодская область"},
+	" череповец":             {Name: "Череповец", Region: "Вологодская область"},
+	" вологде":               {Name: "Вологда", Region: "Вологодская область"},
+	" гатчин*":               {Name: "Гатчина", Region: "Ленинградская область"},
+	" выборг*":               {Name: "Выборг", Region: "Ленинградская область"},
+	" калининграде":          {Name: "Калининград", Region: "Калининградская область"},
+	" рыбинск":               {Name: "Рыбинск", Region: "Ярославская область"},
+	" твери":                 {Name: "Тверь", Region: "Тверская область"},
+	" оренбурге":             {Name: "Оренбург", Region: "Оренбургская область"},
+	" орске":                 {Name: "Орск", Region: "Оренбургская область"},
+	" махачкал*":             {Name: "Махачкала", Region: "Дагестан"},
+	" дербент*":              {Name: "Дербент", Region: "Дагестан"},
+	" хасавюрт*":             {Name: "Хасавюрт", Region: "Дагестан"},
+	" пятигорск":             {Name: "Пятигорск", Region: "Ставропольский край"},
+	" кисловодск":            {Name: "Кисловодск", Region: "Ставропольский край"},
+	" невинномысск":          {Name: "Невинномысск", Region: "Ставропольский край"},
+	" ставрополе":            {Name: "Ставрополь", Region: "Ставропольский край"},
+	" ижевск":                {Name: "Ижевск", Region: "Удмуртия"},
+	" чебоксар*":             {Name: "Чебоксары", Region: "Чувашия"},
+	" димитровград*":         {Name: "Димитровград", Region: "Ульяновская область"},
+	" пензе":                 {Name: "Пенза", Region: "Пензенская область"},
+	" астрахани":             {Name: "Астрахань", Region: "Астраханская область"},
+	" белгороде":             {Name: "Белгород", Region: "Белгородская область"},
+	" старом осколе":         {Name: "Старый Оскол", Region: "Белгородская область"},
+	" старый оскол":          {Name: "Старый Оскол", Region: "Белгородская область"},
+	" симферопол*":           {Name: "Симферополь", Region: "Республика Крым"},
+	" керчи":                 {Name: "Керчь", Region: "Республика Крым"},
+	" мариупол*":             {Name: "Мариуполь", Region: "Донецкая Народная Республика"},
+	" горловк*":              {Name: "Горловка", Region: "Донецкая Народная Республика"},
+}
@@ -0,0 +1,212 @@
+package analyzer
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ScrpTrx-Go/GoTGParse/internal/domain/model"
+	pkg "github.com/ScrpTrx-Go/GoTGParse/pkg/logger"
+	"gopkg.in/yaml.v3"
+)
+
+// EmbeddedDictionariesVersion is the version of the compiled-in dictionaries.
+const EmbeddedDictionariesVersion = "embedded"
+
+// DictionaryBundle is the on-disk form of Dictionaries. JSON bundles are read
+// as well, since JSON is a subset of YAML.
+type DictionaryBundle struct {
+	Version          string                    `yaml:"version"`
+	Prefix           []string                  `yaml:"prefix"`
+	PrefixIC         []string                  `yaml:"prefix_ic"`
+	Verbs            []string                  `yaml:"verbs"`
+	PSK              []string                  `yaml:"psk"`
+	ErrandBody       []string                  `yaml:"errand_body"`
+	Regions          map[string]string         `yaml:"regions"`
+	Exceptions       []string                  `yaml:"exceptions"`
+	Types            []string                  `yaml:"types"`
+	CanonicalRegions []string                  `yaml:"canonical_regions,omitempty"`
+	Localities       map[string]model.Locality `yaml:"localities,omitempty"`
+	Categories       []Category                `yaml:"categories,omitempty"`
+	PlaceExclusions  []string                  `yaml:"place_exclusions,omitempty"`
+}
+
+type FileDictionariesCreator struct {
+	path     string
+	log      pkg.Logger
+	fallback DictionariesCreator
+}
+
+// NewFileDictionariesCreator returns a creator that loads the bundle at path
+// and falls back to the embedded dictionaries if it is missing or invalid.
+func NewFileDictionariesCreator(path string, log pkg.Logger) DictionariesCreator {
+	return &FileDictionariesCreator{
+		path:     path,
+		log:      log,
+		fallback: NewDictionariesCreator(),
+	}
+}
+
+func (c *FileDictionariesCreator) CreateDictionaries() *Dictionaries {
+	if c.path == "" {
+		return c.fallback.CreateDictionaries()
+	}
+	dict, err := LoadDictionaries(c.path)
+	if err != nil {
+		c.log.Error("Failed to load dictionaries, using embedded defaults", "path", c.path, "err", err)
+		return c.fallback.CreateDictionaries()
+	}
+	c.log.Info("Dictionaries loaded", "path", c.path, "version", dict.Version)
+	return dict
+}
+
+func LoadDictionaries(path string) (*Dictionaries, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseDictionaries(data)
+}
+
+func ParseDictionaries(data []byte) (*Dictionaries, error) {
+	var bundle DictionaryBundle
+	if err := yaml.Unmarshal(data, &bundle); err != nil {
+		return nil, fmt.Errorf("parse dictionary bundle: %w", err)
+	}
+	if err := bundle.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid dictionary bundle %q: %w", bundle.Version, err)
+	}
+	return &Dictionaries{
+		Version:               bundle.Version,
+		PrefixDictionary:      bundle.Prefix,
+		PrefixDictionaryIC:    bundle.PrefixIC,
+		VerbsDictionary:       bundle.Verbs,
+		PSKDictionary:         bundle.PSK,
+		ErrandBodyDictionary:  bundle.ErrandBody,
+		RegionsAllias:         bundle.Regions,
+		ExceptionsDictonary:   bundle.Exceptions,
+		ErrandTypesDictionary: bundle.Types,
+		Localities:            bundle.Localities,
+		Categories:            bundle.Categories,
+		PlaceExclusions:       bundle.PlaceExclusions,
+	}, nil
+}
+
+// Validate reports all problems of the bundle at once. Region keys must map to
+// a canonical region name; if the bundle has no canonical_regions list, the
+// names of the embedded dictionaries are used.
+func (b *DictionaryBundle) Validate() error {
+	var errs []error
+	if strings.TrimSpace(b.Version) == "" {
+		errs = append(errs, errors.New("version is empty"))
+	}
+
+	lists := []struct {
+		name    string
+		entries []string
+		lower   bool
+	}{
+		{"prefix", b.Prefix, false},
+		{"prefix_ic", b.PrefixIC, false},
+		{"verbs", b.Verbs, true},
+		{"psk", b.PSK, true},
+		{"errand_body", b.ErrandBody, true},
+		{"exceptions", b.Exceptions, false},
+		{"types", b.Types, true},
+	}
+	for _, list := range lists {
+		errs = append(errs, validateList(list.name, list.entries, list.lower)...)
+	}
+
+	if len(b.Regions) == 0 {
+		errs = append(errs, errors.New("regions: dictionary is empty"))
+	}
+
+	canonical := make(map[string]struct{})
+	if len(b.CanonicalRegions) > 0 {
+		errs = append(errs, validateList("canonical_regions", b.CanonicalRegions, false)...)
+		for _, name := range b.CanonicalRegions {
+			canonical[name] = struct{}{}
+		}
+	} else {
+		for _, name := range regionsMap {
+			canonical[name] = struct{}{}
+		}
+	}
+
+	for key, name := range b.Regions {
+		if strings.TrimSpace(key) == "" {
+			errs = append(errs, fmt.Errorf("regions: empty key for %q", name))
+		}
+		if strings.ToLower(key) != key {
+			errs = append(errs, fmt.Errorf("regions: key %q must be lower case", key))
+		}
+		if _, ok := canonical[name]; !ok {
+			errs = append(errs, fmt.Errorf("regions: key %q maps to unknown region %q", key, name))
+		}
+	}
+	for key, locality := range b.Localities {
+		if strings.TrimSpace(key) == "" {
+			errs = append(errs, fmt.Errorf("localities: empty key for %q", locality.Name))
+		}
+		if strings.ToLower(key) != key {
+			errs = append(errs, fmt.Errorf("localities: key %q must be lower case", key))
+		}
+		if strings.Contains(strings.TrimSuffix(key, "*"), "*") {
+			errs = append(errs, fmt.Errorf("localities: \"*\" is allowed only at the end of %q", key))
+		}
+		if strings.TrimSpace(locality.Name) == "" {
+			errs = append(errs, fmt.Errorf("localities: key %q has no name", key))
+		}
+		if _, ok := canonical[locality.Region]; !ok {
+			errs = append(errs, fmt.Errorf("localities: key %q maps to unknown region %q", key, locality.Region))
+		}
+	}
+	seenCategories := make(map[string]struct{}, len(b.Categories))
+	for i, category := range b.Categories {
+		if strings.TrimSpace(category.Name) == "" {
+			errs = append(errs, fmt.Errorf("categories[%d]: empty name", i))
+		}
+		if _, ok := seenCategories[category.Name]; ok {
+			errs = append(errs, fmt.Errorf("categories[%d]: duplicate name %q", i, category.Name))
+		}
+		seenCategories[category.Name] = struct{}{}
+		errs = append(errs, validateList("categories."+category.Name, category.Terms, true)...)
+	}
+	if len(b.PlaceExclusions) > 0 {
+		errs = append(errs, validateList("place_exclusions", b.PlaceExclusions, true)...)
+	}
+	for _, name := range b.Exceptions {
+		if _, ok := canonical[name]; !ok {
+			errs = append(errs, fmt.Errorf("exceptions: unknown region %q", name))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func validateList(name string, entries []string, lower bool) []error {
+	var errs []error
+	if len(entries) == 0 {
+		errs = append(errs, fmt.Errorf("%s: dictionary is empty", name))
+	}
+	seen := make(map[string]struct{}, len(entries))
+	for i, entry := range entries {
+		if strings.TrimSpace(entry) == "" {
+			errs = append(errs, fmt.Errorf("%s[%d]: empty entry", name, i))
+			continue
+		}
+		if _, ok := seen[entry]; ok {
+			errs = append(errs, fmt.Errorf("%s[%d]: duplicate entry %q", name, i, entry))
+		}
+		seen[entry] = struct{}{}
+		if strings.Contains(strings.TrimSuffix(entry, "*"), "*") {
+			errs = append(errs, fmt.Errorf("%s[%d]: \"*\" is allowed only at the end of %q", name, i, entry))
+		}
+		if lower && strings.ToLower(entry) != entry {
+			errs = append(errs, fmt.Errorf("%s[%d]: entry %q must be lower case", name, i, entry))
+		}
+	}
+	return errs
+}
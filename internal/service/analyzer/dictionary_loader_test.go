@@ -0,0 +1,69 @@
+package analyzer_test
+
+import (
+	"maps"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/ScrpTrx-Go/GoTGParse/internal/service/analyzer"
+)
+
+func TestLoadShippedDictionaries(t *testing.T) {
+	_, filename, _, _ := runtime.Caller(0)
+	path := filepath.Join(filepath.Dir(filename), "..", "..", "config", "dictionaries.yaml")
+
+	dict, err := analyzer.LoadDictionaries(path)
+	if err != nil {
+		t.Fatalf("shipped dictionaries are invalid: %v", err)
+	}
+	embedded := analyzer.NewDictionariesCreator().CreateDictionaries()
+	if len(dict.RegionsAllias) != len(embedded.RegionsAllias) {
+		t.Fatalf("shipped regions differ from embedded: %d vs %d", len(dict.RegionsAllias), len(embedded.RegionsAllias))
+	}
+	if !maps.Equal(dict.Localities, embedded.Localities) {
+		t.Fatalf("shipped localities differ from embedded: %d vs %d", len(dict.Localities), len(embedded.Localities))
+	}
+	sameCategory := func(a, b analyzer.Category) bool { return a.Name == b.Name && slices.Equal(a.Terms, b.Terms) }
+	if !slices.EqualFunc(dict.Categories, embedded.Categories, sameCategory) {
+		t.Fatalf("shipped categories differ from embedded: %v vs %v", dict.Categories, embedded.Categories)
+	}
+	if !slices.Equal(dict.ErrandBodyDictionary, embedded.ErrandBodyDictionary) {
+		t.Fatalf("shipped errand body differs from embedded: %v vs %v", dict.ErrandBodyDictionary, embedded.ErrandBodyDictionary)
+	}
+	if !slices.Equal(dict.PlaceExclusions, embedded.PlaceExclusions) {
+		t.Fatalf("shipped place exclusions differ from embedded: %v vs %v", dict.PlaceExclusions, embedded.PlaceExclusions)
+	}
+}
+
+func TestParseDictionariesValidation(t *testing.T) {
+	bundle := `
+version: "test"
+prefix: ["❗️", ""]
+prefix_ic: ["🟥🟥🟥🟥"]
+verbs: ["поруч", "поруч"]
+psk: ["Глав"]
+errand_body: ["област"]
+regions:
+  " брянск": "Брянская область"
+  " атлантид": "Атлантида"
+exceptions: ["ГВСУ"]
+types: ["провести"]
+`
+	_, err := analyzer.ParseDictionaries([]byte(bundle))
+	if err == nil {
+		t.Fatal("expected validation error")
+	}
+	for _, want := range []string{
+		`prefix[1]: empty entry`,
+		`verbs[1]: duplicate entry "поруч"`,
+		`psk[0]: entry "Глав" must be lower case`,
+		`maps to unknown region "Атлантида"`,
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not mention %q", err, want)
+		}
+	}
+}
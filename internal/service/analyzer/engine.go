@@ -0,0 +1,434 @@
+package analyzer
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ScrpTrx-Go/GoTGParse/internal/domain/model"
+)
+
+// Engine classifies posts with one version of the dictionaries. It is never
+// modified after creation, so the workers share one Engine; a dictionary
+// reload builds a new Engine.
+type Engine struct {
+	version  string
+	matchers Matchers
+	regions  []string
+	dict     Dictionaries
+	rules    ChannelRules
+	literals map[string]*TermMatcher
+}
+
+func NewEngine(factory MatchersCreator, regions []string, dict Dictionaries, rules ChannelRules) *Engine {
+	matchers := factory.CreateMatchers()
+	literals := make(map[string]*TermMatcher)
+	for _, literal := range rules.literals() {
+		literals[literal] = matchers.literalMatcher(literal)
+	}
+	return &Engine{
+		version:  dict.Version,
+		matchers: matchers,
+		regions:  regions,
+		dict:     dict,
+		rules:    rules,
+		literals: literals,
+	}
+}
+
+func (e *Engine) Version() string {
+	return e.version
+}
+
+// Confidences of the decisions that are not computed from match counts.
+const (
+	confidenceSure    = 1.0
+	confidenceLikely  = 0.75
+	confidenceMaybe   = 0.5
+	confidenceUnknown = 0.0
+)
+
+func (e *Engine) IsErrand(post *model.Post) bool {
+	return e.ErrandConfidence(post) > 0
+}
+
+// ErrandConfidence returns how sure the channel rule is that the post is an
+// errand, from 0 for "not an errand" to 1.
+func (e *Engine) ErrandConfidence(post *model.Post) float64 {
+	rule, ok := e.rules[post.Username]
+	if !ok {
+		return confidenceUnknown
+	}
+	switch rule.Detector {
+	case DetectorTitle:
+		return e.CheckErrandTitle(post, rule)
+	case DetectorPrefix:
+		return e.TitleHasPrefix(post, rule)
+	case DetectorExpression:
+		return e.CheckExpressions(post, rule)
+	}
+	return confidenceUnknown
+}
+
+// TitleHasPrefix is sure about a prefix that opens the post, less about one
+// further in the title and least about one in the body.
+func (e *Engine) TitleHasPrefix(post *model.Post, rule *ChannelRule) float64 {
+	for _, name := range rule.Dictionaries {
+		m := e.matchers.ByName(name)
+		matches := e.match(post, name, "text", m, post.Text)
+		if len(matches) == 0 {
+			continue
+		}
+		post.MatchedRule = DetectorPrefix
+		offset := len(post.Text)
+		for _, loc := range m.Locate(post.Text, matches) {
+			offset = min(offset, loc.Offset)
+		}
+		title, _, _ := strings.Cut(post.Text, "\n")
+		switch {
+		case offset == 0:
+			return confidenceSure
+		case offset < utf8.RuneCountInString(title):
+			return confidenceLikely
+		}
+		return confidenceMaybe
+	}
+	return confidenceUnknown
+}
+
+// CheckExpressions evaluates the expressions of the rule in order and records
+// the name of the first one that holds on the post.
+func (e *Engine) CheckExpressions(post *model.Post, rule *ChannelRule) float64 {
+	env := &postEnv{engine: e, post: post}
+	for _, named := range rule.expressions {
+		if !named.expr.eval(env) {
+			continue
+		}
+		post.MatchedRule = named.name
+		if named.maybe {
+			return confidenceMaybe
+		}
+		return confidenceSure
+	}
+	return confidenceUnknown
+}
+
+// CheckErrandTitle accepts a post whose title matches at least MaybeMatches of
+// the rule dictionaries. The confidence is the count of matched dictionaries
+// relative to MinMatches.
+func (e *Engine) CheckErrandTitle(post *model.Post, rule *ChannelRule) float64 {
+	errandTitle := e.GetLowTitle(post.Text)
+	matchesCounter := 0
+	for _, name := range rule.Dictionaries {
+		matches := e.match(post, name, "title", e.matchers.ByName(name), errandTitle)
+		if len(matches) > 0 {
+			matchesCounter++
+		}
+	}
+
+	if matchesCounter < rule.MaybeMatches {
+		return confidenceUnknown
+	}
+	post.MatchedRule = DetectorTitle
+	return min(confidenceSure, float64(matchesCounter)/float64(rule.MinMatches))
+}
+
+// match runs the matcher over text and, if the post is traced, records the
+// terms found with the dictionary and the region they were found in.
+func (e *Engine) match(post *model.Post, dictionary, region string, m *TermMatcher, text string) []int {
+	matches := m.Match([]byte(text))
+	if post.Trace == nil || len(matches) == 0 {
+		return matches
+	}
+	for _, loc := range m.Locate(text, matches) {
+		post.Trace.Matches = append(post.Trace.Matches, model.TraceMatch{
+			Dictionary: dictionary,
+			Region:     region,
+			Term:       loc.Term,
+			Offset:     loc.Offset,
+		})
+	}
+	return matches
+}
+
+func (e *Engine) GetLowTitle(text string) string {
+	split := strings.SplitN(text, "\n", 2)
+	title := strings.ToLower(strings.Join(strings.Fields(split[0]), " "))
+	return title
+}
+
+// RegionResult is the primary region of an errand and the other regions it
+// mentions, e.g. where someone involved comes from.
+type RegionResult struct {
+	Regions    []string
+	Secondary  []string
+	Confidence map[string]float64
+	Localities []model.Locality
+}
+
+// ExtractRegions returns the region of the errand with its confidence. Cities
+// of the gazetteer name their region as well and are returned as
+// localities. Regions found in the errand body are trusted more than regions
+// found elsewhere in the text. If several regions are mentioned, the one with
+// the best mentions by position, preposition and nearness to an errand verb is
+// primary and the others are secondary; the closer their scores, the lower
+// the confidence.
+func (e *Engine) ExtractRegions(post *model.Post) RegionResult {
+	source, base := "body", confidenceSure
+	text := e.FindErrandBody(post)
+	matches := e.match(post, "regions", source, e.matchers.Regions, text)
+	localityMatches := e.match(post, "localities", source, e.matchers.Localities, text)
+
+	if len(matches) == 0 && len(localityMatches) == 0 {
+		source, base = "text", confidenceMaybe
+		text = strings.ToLower(post.Text)
+		matches = e.match(post, "regions", source, e.matchers.Regions, text)
+		localityMatches = e.match(post, "localities", source, e.matchers.Localities, text)
+	}
+	localities := e.FoundLocalities(localityMatches)
+	candidates := withLocalityRegions(e.FoundRegionsName(matches), localities)
+	result := e.CheckException(candidates)
+	if len(result) <= 1 {
+		traceRegions(post, source, candidates, result, "")
+		return RegionResult{
+			Regions:    result,
+			Secondary:  secondaryRegions(candidates, result),
+			Confidence: regionConfidence(result, base),
+			Localities: localitiesIn(localities, result),
+		}
+	}
+
+	scores := e.regionScores(text, matches, localityMatches)
+	primary, secondary, margin := primaryRegion(result, scores)
+	result = []string{primary}
+	traceRegions(post, source, candidates, result, fmt.Sprintf("secondary mention, %s scores higher", primary))
+	return RegionResult{
+		Regions:    result,
+		Secondary:  secondary,
+		Confidence: regionConfidence(result, base*(confidenceMaybe+(1-confidenceMaybe)*margin)),
+		Localities: localitiesIn(localities, result),
+	}
+}
+
+// secondaryRegions returns the candidates that were not chosen.
+func secondaryRegions(candidates, chosen []string) []string {
+	var secondary []string
+	for _, region := range candidates {
+		if !slices.Contains(chosen, region) {
+			secondary = append(secondary, region)
+		}
+	}
+	return secondary
+}
+
+// FoundLocalities returns the distinct localities with the given match indices.
+func (e *Engine) FoundLocalities(matches []int) []model.Locality {
+	var found []model.Locality
+	for _, match := range matches {
+		locality, ok := e.dict.Localities[e.matchers.Localities.Term(match)]
+		if ok && !slices.Contains(found, locality) {
+			found = append(found, locality)
+		}
+	}
+	return found
+}
+
+// withLocalityRegions adds the regions of the localities that are not named
+// already.
+func withLocalityRegions(regions []string, localities []model.Locality) []string {
+	for _, locality := range localities {
+		if !slices.Contains(regions, locality.Region) {
+			regions = append(regions, locality.Region)
+		}
+	}
+	return regions
+}
+
+// localitiesIn keeps the localities of the chosen regions.
+func localitiesIn(localities []model.Locality, regions []string) []model.Locality {
+	var result []model.Locality
+	for _, locality := range localities {
+		if slices.Contains(regions, locality.Region) {
+			result = append(result, locality)
+		}
+	}
+	return result
+}
+
+func regionConfidence(regions []string, base float64) map[string]float64 {
+	if len(regions) == 0 {
+		return nil
+	}
+	confidence := make(map[string]float64, len(regions))
+	for _, region := range regions {
+		confidence[region] = base / float64(len(regions))
+	}
+	return confidence
+}
+
+// traceRegions records the decision about each region candidate. Candidates
+// missing from chosen are secondary with reason, or in favour of an exception
+// region if reason is empty.
+func traceRegions(post *model.Post, source string, candidates, chosen []string, reason string) {
+	if post.Trace == nil {
+		return
+	}
+	if reason == "" && len(chosen) == 1 && len(candidates) > 1 {
+		reason = "exception region " + chosen[0] + " takes precedence"
+	}
+	for _, name := range candidates {
+		region := model.TraceRegion{
+			Name:   name,
+			Source: source,
+			Status: model.RegionSecondary,
+			Reason: reason,
+		}
+		if slices.Contains(chosen, name) {
+			region.Status = model.RegionChosen
+			region.Reason = ""
+		}
+		post.Trace.Regions = append(post.Trace.Regions, region)
+	}
+}
+
+func (e *Engine) CheckException(errandRegions []string) []string {
+	for _, region := range errandRegions {
+		for _, exception := range e.dict.ExceptionsDictonary {
+			if region == exception {
+				errandRegions = []string{exception}
+				break
+			}
+		}
+	}
+	return errandRegions
+}
+
+func (e *Engine) FindErrandBody(post *model.Post) string {
+	body, _ := e.errandBody(post)
+	return body
+}
+
+// errandBody returns the lowered paragraph with the most errand body terms
+// and its index, or the whole lowered text and -1 if no paragraph has any.
+func (e *Engine) errandBody(post *model.Post) (string, int) {
+	loweredText := strings.ToLower(post.Text)
+	paragraphs := strings.Split(loweredText, "\n")
+	var maxLen int
+	var errandBody string
+	paragraph := -1
+	for idx, para := range paragraphs {
+		if idx == 0 {
+			continue
+		}
+		currentLen := len(e.matchers.ErrandBodyMatcher.Match([]byte(para)))
+		if currentLen > maxLen {
+			maxLen = currentLen
+			errandBody = para
+			paragraph = idx
+		}
+	}
+	normalizedErrandBody := strings.TrimSpace(errandBody)
+	if normalizedErrandBody == "" {
+		normalizedErrandBody = strings.ToLower(strings.TrimSpace(post.Text))
+		paragraph = -1
+	}
+	if post.Trace != nil {
+		post.Trace.Title = e.GetLowTitle(post.Text)
+		post.Trace.ErrandBody = normalizedErrandBody
+		post.Trace.ErrandBodyParagraph = paragraph
+	}
+	return normalizedErrandBody, paragraph
+}
+
+func (e *Engine) FoundRegionsName(matches []int) []string {
+	seen := make(map[string]struct{})
+	errandRegions := make([]string, 0)
+
+	for _, match := range matches {
+		if region, ok := e.dict.RegionsAllias[e.regions[match]]; ok {
+			if _, ok := seen[region]; !ok {
+				seen[region] = struct{}{}
+				errandRegions = append(errandRegions, region)
+			}
+		}
+	}
+	return errandRegions
+}
+
+// TypeConfidence returns how sure the analyzer is that the errand is of the
+// special type: one type term gives confidenceLikely, more terms make it sure.
+// Terms found only because no errand body was found count for less.
+func (e *Engine) TypeConfidence(post *model.Post) float64 {
+	text, paragraph := e.errandBody(post)
+	matches := e.match(post, DictTypes, "body", e.matchers.ErrandType, text)
+	confidence := confidenceUnknown
+	switch {
+	case len(matches) > 1:
+		confidence = confidenceSure
+	case len(matches) == 1:
+		confidence = confidenceLikely
+	}
+	if paragraph < 0 {
+		confidence *= confidenceLikely
+	}
+	return confidence
+}
+
+// Categories returns the categories of the taxonomy whose terms occur in the
+// post. The whole text is matched since the incident is often described
+// outside the errand body, e.g. in the title.
+func (e *Engine) Categories(post *model.Post) []string {
+	text := strings.ToLower(post.Text)
+	var found []string
+	for _, category := range e.matchers.Categories {
+		if len(e.match(post, "category:"+category.Name, "text", category.Matcher, text)) > 0 {
+			found = append(found, category.Name)
+		}
+	}
+	return found
+}
+
+// postEnv evaluates expressions against one post, computing each text region
+// at most once.
+type postEnv struct {
+	engine  *Engine
+	post    *model.Post
+	regions map[textRegion]string
+}
+
+func (p *postEnv) match(dictionary, literal string, r textRegion) bool {
+	m := p.engine.matchers.ByName(dictionary)
+	name := dictionary
+	if literal != "" {
+		m = p.engine.literals[literal]
+		name = "literal"
+	}
+	if m == nil {
+		return false
+	}
+	return len(p.engine.match(p.post, name, r.String(), m, p.region(r))) > 0
+}
+
+func (p *postEnv) region(r textRegion) string {
+	if text, ok := p.regions[r]; ok {
+		return text
+	}
+	var text string
+	switch r.kind {
+	case regionTitle:
+		text = p.engine.GetLowTitle(p.post.Text)
+	case regionBody:
+		text = p.engine.FindErrandBody(p.post)
+	case regionFirst:
+		runes := []rune(strings.ToLower(p.post.Text))
+		text = string(runes[:min(r.n, len(runes))])
+	default:
+		text = strings.ToLower(p.post.Text)
+	}
+	if p.regions == nil {
+		p.regions = make(map[textRegion]string)
+	}
+	p.regions[r] = text
+	return text
+}
@@ -0,0 +1,149 @@
+package analyzer_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/ScrpTrx-Go/GoTGParse/internal/config"
+	"github.com/ScrpTrx-Go/GoTGParse/internal/domain/model"
+	"github.com/ScrpTrx-Go/GoTGParse/internal/service/analyzer"
+)
+
+func TestEngineTrace(t *testing.T) {
+	rules, err := analyzer.NewChannelRules([]config.ChannelRule{{
+		Username:      "sledcom_press",
+		Detector:      analyzer.DetectorTitle,
+		Dictionaries:  []string{analyzer.DictPrefix, analyzer.DictVerbs, analyzer.DictPSK},
+		ReportSection: config.SectionSledcom,
+	}})
+	if err != nil {
+		t.Fatalf("NewChannelRules: %v", err)
+	}
+	dict := analyzer.NewDictionariesCreator().CreateDictionaries()
+	regions := analyzer.GetRegionKeys(dict.RegionsAllias)
+	engine := analyzer.NewEngine(analyzer.NewMatcherCreator(dict, regions, nil), regions, *dict, rules)
+
+	post := &model.Post{
+		Username: "sledcom_press",
+		Text:     "❗️Председатель СК поручил доложить\nВступление\nРуководителю следственного управления по Самарской области поручено доложить",
+		Trace:    &model.AnalysisTrace{},
+	}
+	if got := engine.ErrandConfidence(post); got != 1 {
+		t.Fatalf("ErrandConfidence = %v, want 1", got)
+	}
+	if place := engine.ExtractRegions(post); place.Confidence["Самарская область"] != 1 {
+		t.Errorf("region confidence = %v", place.Confidence)
+	}
+
+	trace := post.Trace
+	if trace.ErrandBodyParagraph != 2 {
+		t.Errorf("errand body paragraph = %d, want 2", trace.ErrandBodyParagraph)
+	}
+	var titleMatches int
+	for _, m := range trace.Matches {
+		if m.Region == "title" {
+			titleMatches++
+		}
+	}
+	if titleMatches < 3 {
+		t.Errorf("title matches = %v, want at least 3", trace.Matches)
+	}
+	if len(trace.Regions) != 1 || trace.Regions[0].Status != model.RegionChosen || trace.Regions[0].Source != "body" {
+		t.Errorf("regions = %+v", trace.Regions)
+	}
+
+	post = &model.Post{
+		Username: "sledcom_press",
+		Text:     "❗️Председатель СК поручил доложить\nРуководителю следственного управления поручено доложить о нападении в Нижнем Тагиле",
+	}
+	place := engine.ExtractRegions(post)
+	want := model.Locality{Name: "Нижний Тагил", Region: "Свердловская область"}
+	if len(place.Regions) != 1 || place.Regions[0] != want.Region || len(place.Localities) != 1 || place.Localities[0] != want {
+		t.Errorf("place = %+v, want %+v", place, want)
+	}
+}
+
+func TestEngineLocalityWordForms(t *testing.T) {
+	dict := analyzer.NewDictionariesCreator().CreateDictionaries()
+	regions := analyzer.GetRegionKeys(dict.RegionsAllias)
+
+	for name, normalizer := range map[string]analyzer.Normalizer{
+		"raw":     nil,
+		"stemmed": analyzer.NewRussianStemmer(),
+	} {
+		engine := analyzer.NewEngine(analyzer.NewMatcherCreator(dict, regions, normalizer), regions, *dict, analyzer.ChannelRules{})
+		for _, tc := range []struct {
+			text string
+			want string
+		}{
+			{"Председатель СК поручил доложить о проверке УФАС по Самарской области", "Самарская область"},
+			{"Председатель СК поручил доложить о нападении на Казанском вокзале в Москве", "Москва"},
+			{"Председатель СК поручил доложить о нападении в Уфе", "Башкортостан"},
+			{"Председатель СК поручил доложить о нападении в Казани", "Татарстан"},
+			{"Председатель СК поручил доложить о нападении в Братске", "Иркутская область"},
+			{"Председатель СК поручил доложить о нападении в Сочи.", "Краснодарский край"},
+			{"Председатель СК поручил доложить о вандализме у братской могилы в Брянской области", "Брянская область"},
+			{"Председатель СК поручил проверить сочинение школьника из Омской области", "Омская область"},
+		} {
+			place := engine.ExtractRegions(&model.Post{Text: tc.text})
+			if len(place.Regions) != 1 || place.Regions[0] != tc.want || len(place.Secondary) != 0 {
+				t.Errorf("%s: %q: regions = %v, secondary = %v, want %s", name, tc.text, place.Regions, place.Secondary, tc.want)
+			}
+		}
+	}
+}
+
+func TestEngineCategories(t *testing.T) {
+	dict := analyzer.NewDictionariesCreator().CreateDictionaries()
+	regions := analyzer.GetRegionKeys(dict.RegionsAllias)
+	engine := analyzer.NewEngine(analyzer.NewMatcherCreator(dict, regions, nil), regions, *dict, analyzer.ChannelRules{})
+
+	post := &model.Post{
+		Text: "❗️Председатель СК поручил доложить об отсутствии отопления\nВ аварийном доме живут семьи с детьми",
+	}
+	got := engine.Categories(post)
+	if want := []string{"Права детей", "ЖКХ"}; !slices.Equal(got, want) {
+		t.Errorf("Categories = %v, want %v", got, want)
+	}
+	for _, text := range []string{
+		"Председатель СК поручил доложить",
+		"Председатель СК поручил доложить о ходе расследования, назначена судебно-медицинская экспертиза",
+		"На месте пожара работали аварийно-спасательные службы, Председатель СК поручил доложить",
+		"Председатель СК поручил доложить, почему к заявителю не приезжали сотрудники полиции",
+	} {
+		if got := engine.Categories(&model.Post{Text: text}); len(got) != 0 {
+			t.Errorf("Categories(%q) = %v, want none", text, got)
+		}
+	}
+	if got := engine.Categories(&model.Post{Text: "Пациенту не оказали медицинскую помощь"}); !slices.Equal(got, []string{"Здравоохранение"}) {
+		t.Errorf("Categories = %v, want Здравоохранение", got)
+	}
+	if got := engine.Categories(&model.Post{Text: "Приезжие избили подростка"}); !slices.Equal(got, []string{"Мигранты"}) {
+		t.Errorf("Categories = %v, want Мигранты", got)
+	}
+}
+
+func TestEngineRegionAmbiguity(t *testing.T) {
+	dict := analyzer.NewDictionariesCreator().CreateDictionaries()
+	regions := analyzer.GetRegionKeys(dict.RegionsAllias)
+	engine := analyzer.NewEngine(analyzer.NewMatcherCreator(dict, regions, nil), regions, *dict, analyzer.ChannelRules{})
+
+	post := &model.Post{
+		Text: "❗️Председатель СК поручил доложить\nВступление\n" +
+			"Руководителю ГСУ СК России по Московской области поручено доложить о ходе расследования " +
+			"нападения на уроженца Курской области",
+		Trace: &model.AnalysisTrace{},
+	}
+	place := engine.ExtractRegions(post)
+	if !slices.Equal(place.Regions, []string{"Московская область"}) || !slices.Equal(place.Secondary, []string{"Курская область"}) {
+		t.Fatalf("place = %+v, want Московская область with Курская область secondary", place)
+	}
+	if c := place.Confidence["Московская область"]; c <= 0.5 || c > 1 {
+		t.Errorf("confidence = %v, want above 0.5", c)
+	}
+	for _, region := range post.Trace.Regions {
+		if region.Name == "Курская область" && region.Status != model.RegionSecondary {
+			t.Errorf("trace of Курская область = %+v, want secondary", region)
+		}
+	}
+}
@@ -0,0 +1,328 @@
+package analyzer
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Expression is a compiled errand detection rule. The language is
+//
+//	expr    = or
+//	or      = and { "OR" and }
+//	and     = unary { "AND" unary }
+//	unary   = "NOT" unary | primary
+//	primary = "(" expr ")" | term [ "IN" region ]
+//	term    = dictionary name | "quoted literal"
+//	region  = "title" | "body" | "text" | "first" "(" N ")"
+//
+// A term is true if the dictionary or the literal matches the region. The
+// default region is text, the whole lowered post; title is its first line,
+// body is the errand paragraph found by FindErrandBody and first(N) is the
+// first N characters of the text. Keywords are case-insensitive.
+//
+//	prefix IN title AND (verbs IN title OR "бастрыкин" IN title) AND NOT "опроверг"
+type Expression struct {
+	source   string
+	root     exprNode
+	literals []string
+}
+
+const (
+	regionText = iota
+	regionTitle
+	regionBody
+	regionFirst
+)
+
+type textRegion struct {
+	kind int
+	n    int
+}
+
+func (r textRegion) String() string {
+	switch r.kind {
+	case regionTitle:
+		return "title"
+	case regionBody:
+		return "body"
+	case regionFirst:
+		return fmt.Sprintf("first(%d)", r.n)
+	}
+	return "text"
+}
+
+// exprEnv gives an expression access to the matchers and the regions of a post.
+type exprEnv interface {
+	match(dictionary, literal string, r textRegion) bool
+}
+
+type exprNode interface {
+	eval(env exprEnv) bool
+}
+
+type andNode struct{ left, right exprNode }
+
+type orNode struct{ left, right exprNode }
+
+type notNode struct{ x exprNode }
+
+type termNode struct {
+	dictionary string
+	literal    string
+	region     textRegion
+}
+
+func (n andNode) eval(env exprEnv) bool { return n.left.eval(env) && n.right.eval(env) }
+
+func (n orNode) eval(env exprEnv) bool { return n.left.eval(env) || n.right.eval(env) }
+
+func (n notNode) eval(env exprEnv) bool { return !n.x.eval(env) }
+
+func (n termNode) eval(env exprEnv) bool {
+	return env.match(n.dictionary, n.literal, n.region)
+}
+
+func (x *Expression) String() string {
+	return x.source
+}
+
+// Literals returns the quoted literals of the expression in lower case.
+func (x *Expression) Literals() []string {
+	return x.literals
+}
+
+func (x *Expression) eval(env exprEnv) bool {
+	return x.root.eval(env)
+}
+
+func ParseExpression(source string) (*Expression, error) {
+	tokens, err := lexExpression(source)
+	if err != nil {
+		return nil, err
+	}
+	p := &exprParser{tokens: tokens}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokEOF {
+		return nil, fmt.Errorf("unexpected %q at %d", tok.text, tok.pos)
+	}
+	return &Expression{
+		source:   source,
+		root:     root,
+		literals: p.literals,
+	}, nil
+}
+
+const (
+	tokEOF = iota
+	tokIdent
+	tokString
+	tokNumber
+	tokLParen
+	tokRParen
+)
+
+type exprToken struct {
+	kind int
+	text string
+	pos  int
+}
+
+func lexExpression(src string) ([]exprToken, error) {
+	var tokens []exprToken
+	for i := 0; i < len(src); {
+		r, size := utf8.DecodeRuneInString(src[i:])
+		switch {
+		case unicode.IsSpace(r):
+			i += size
+		case r == '(':
+			tokens = append(tokens, exprToken{kind: tokLParen, text: "(", pos: i})
+			i += size
+		case r == ')':
+			tokens = append(tokens, exprToken{kind: tokRParen, text: ")", pos: i})
+			i += size
+		case r == '"':
+			end := strings.IndexByte(src[i+1:], '"')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated literal at %d", i)
+			}
+			tokens = append(tokens, exprToken{kind: tokString, text: src[i+1 : i+1+end], pos: i})
+			i += end + 2
+		case r >= '0' && r <= '9':
+			start := i
+			for i < len(src) && src[i] >= '0' && src[i] <= '9' {
+				i++
+			}
+			tokens = append(tokens, exprToken{kind: tokNumber, text: src[start:i], pos: start})
+		case unicode.IsLetter(r) || r == '_':
+			start := i
+			for i < len(src) {
+				r, size := utf8.DecodeRuneInString(src[i:])
+				if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' {
+					break
+				}
+				i += size
+			}
+			tokens = append(tokens, exprToken{kind: tokIdent, text: src[start:i], pos: start})
+		default:
+			return nil, fmt.Errorf("unexpected character %q at %d", r, i)
+		}
+	}
+	return append(tokens, exprToken{kind: tokEOF, pos: len(src)}), nil
+}
+
+type exprParser struct {
+	tokens   []exprToken
+	pos      int
+	literals []string
+}
+
+func (p *exprParser) peek() exprToken {
+	return p.tokens[p.pos]
+}
+
+func (p *exprParser) next() exprToken {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *exprParser) keyword(word string) bool {
+	tok := p.peek()
+	if tok.kind == tokIdent && strings.EqualFold(tok.text, word) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *exprParser) parseOr() (exprNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("OR") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orNode{left, right}
+	}
+	return left, nil
+}
+
+func (p *exprParser) parseAnd() (exprNode, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("AND") {
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = andNode{left, right}
+	}
+	return left, nil
+}
+
+func (p *exprParser) parseUnary() (exprNode, error) {
+	if p.keyword("NOT") {
+		x, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{x}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *exprParser) parsePrimary() (exprNode, error) {
+	tok := p.next()
+	var term termNode
+	switch tok.kind {
+	case tokLParen:
+		x, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.kind != tokRParen {
+			return nil, fmt.Errorf("expected ) at %d", closing.pos)
+		}
+		return x, nil
+	case tokString:
+		if tok.text == "" {
+			return nil, fmt.Errorf("empty literal at %d", tok.pos)
+		}
+		term.literal = strings.ToLower(tok.text)
+		p.literals = append(p.literals, term.literal)
+	case tokIdent:
+		if isExprKeyword(tok.text) {
+			return nil, fmt.Errorf("unexpected %q at %d", tok.text, tok.pos)
+		}
+		if !isDictionaryName(tok.text) {
+			return nil, fmt.Errorf("unknown dictionary %q at %d", tok.text, tok.pos)
+		}
+		term.dictionary = tok.text
+	case tokEOF:
+		return nil, fmt.Errorf("unexpected end of expression")
+	default:
+		return nil, fmt.Errorf("unexpected %q at %d", tok.text, tok.pos)
+	}
+
+	if p.keyword("IN") {
+		region, err := p.parseRegion()
+		if err != nil {
+			return nil, err
+		}
+		term.region = region
+	}
+	return term, nil
+}
+
+func (p *exprParser) parseRegion() (textRegion, error) {
+	tok := p.next()
+	if tok.kind != tokIdent {
+		return textRegion{}, fmt.Errorf("expected region at %d", tok.pos)
+	}
+	switch strings.ToLower(tok.text) {
+	case "text":
+		return textRegion{kind: regionText}, nil
+	case "title":
+		return textRegion{kind: regionTitle}, nil
+	case "body":
+		return textRegion{kind: regionBody}, nil
+	case "first":
+		if open := p.next(); open.kind != tokLParen {
+			return textRegion{}, fmt.Errorf("expected ( after first at %d", open.pos)
+		}
+		num := p.next()
+		if num.kind != tokNumber {
+			return textRegion{}, fmt.Errorf("expected number at %d", num.pos)
+		}
+		n, err := strconv.Atoi(num.text)
+		if err != nil || n <= 0 {
+			return textRegion{}, fmt.Errorf("invalid length %q at %d", num.text, num.pos)
+		}
+		if closing := p.next(); closing.kind != tokRParen {
+			return textRegion{}, fmt.Errorf("expected ) at %d", closing.pos)
+		}
+		return textRegion{kind: regionFirst, n: n}, nil
+	}
+	return textRegion{}, fmt.Errorf("unknown region %q at %d", tok.text, tok.pos)
+}
+
+func isExprKeyword(word string) bool {
+	switch strings.ToUpper(word) {
+	case "AND", "OR", "NOT", "IN":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,66 @@
+package analyzer_test
+
+import (
+	"testing"
+
+	"github.com/ScrpTrx-Go/GoTGParse/internal/config"
+	"github.com/ScrpTrx-Go/GoTGParse/internal/domain/model"
+	"github.com/ScrpTrx-Go/GoTGParse/internal/service/analyzer"
+)
+
+func TestParseExpressionErrors(t *testing.T) {
+	for _, src := range []string{
+		``,
+		`prefix AND`,
+		`(prefix OR verbs`,
+		`unknown IN title`,
+		`prefix IN header`,
+		`"опроверг" IN first(0)`,
+		`"unterminated`,
+		`"x" IN first(٣)`,
+		`"x" IN first(1٣)`,
+	} {
+		if _, err := analyzer.ParseExpression(src); err == nil {
+			t.Errorf("expected error for %q", src)
+		}
+	}
+}
+
+func TestExpressionDetector(t *testing.T) {
+	rules, err := analyzer.NewChannelRules([]config.ChannelRule{{
+		Username: "sledcom_press",
+		Detector: analyzer.DetectorExpression,
+		Expressions: []config.ExpressionRule{
+			{Name: "refutation", When: `prefix IN first(3) AND "опроверг"`, Maybe: true},
+			{Name: "errand", When: `prefix IN title AND (verbs IN title OR "Бастрыкин" IN title) AND NOT "опроверг"`},
+		},
+		ReportSection: config.SectionSledcom,
+	}})
+	if err != nil {
+		t.Fatalf("NewChannelRules: %v", err)
+	}
+	dict := analyzer.NewDictionariesCreator().CreateDictionaries()
+	regions := analyzer.GetRegionKeys(dict.RegionsAllias)
+	engine := analyzer.NewEngine(analyzer.NewMatcherCreator(dict, regions, nil), regions, *dict, rules)
+
+	cases := []struct {
+		text       string
+		confidence float64
+		rule       string
+	}{
+		{"❗️Бастрыкин взял на контроль\nТекст", 1, "errand"},
+		{"❗️Председатель поручил доложить\nТекст", 1, "errand"},
+		{"❗️СК опровергает\nТекст", 0.5, "refutation"},
+		{"Председатель поручил доложить\nТекст", 0, ""},
+		{"Новости\n❗️Бастрыкин поручил", 0, ""},
+	}
+	for _, c := range cases {
+		post := &model.Post{Username: "sledcom_press", Text: c.text}
+		if got := engine.ErrandConfidence(post); got != c.confidence {
+			t.Errorf("%q: ErrandConfidence = %v, want %v", c.text, got, c.confidence)
+		}
+		if post.MatchedRule != c.rule {
+			t.Errorf("%q: rule %q, want %q", c.text, post.MatchedRule, c.rule)
+		}
+	}
+}
@@ -7,7 +7,20 @@ import (
 )
 
 type AnalyzePostWorker interface {
-	Run(ctx context.Context, in <-chan *model.Post, out chan<- *model.Post)
+	// Run analyzes posts until in is closed and adds its counters to stats.
+	Run(ctx context.Context, in <-chan *model.Post, out chan<- *model.Post, stats *model.AnalysisStats)
+	Reload(engine *Engine)
+}
+
+// Classifier estimates the probability that a post text is an errand.
+type Classifier interface {
+	Probability(text string) float64
+}
+
+// PostStage runs on analyzed posts after the workers, e.g. to extract the
+// details of errands.
+type PostStage interface {
+	Process(post *model.Post)
 }
 
 type MatchersCreator interface {
@@ -1,39 +1,218 @@
 package analyzer
 
 import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
 	"github.com/cloudflare/ahocorasick"
 )
 
 type DefaultMatchersCreator struct {
-	dict    *Dictionaries
-	regions []string
+	dict       *Dictionaries
+	regions    []string
+	normalizer Normalizer
 }
 
 type Matchers struct {
-	PrefixMatcher     *ahocorasick.Matcher
-	PrefixICMatcher   *ahocorasick.Matcher
-	VerbMatcher       *ahocorasick.Matcher
-	PSKMatcher        *ahocorasick.Matcher
-	ErrandBodyMatcher *ahocorasick.Matcher
-	Regions           *ahocorasick.Matcher
-	ErrandType        *ahocorasick.Matcher
+	PrefixMatcher     *TermMatcher
+	PrefixICMatcher   *TermMatcher
+	VerbMatcher       *TermMatcher
+	PSKMatcher        *TermMatcher
+	ErrandBodyMatcher *TermMatcher
+	Regions           *TermMatcher
+	Localities        *TermMatcher
+	ErrandType        *TermMatcher
+	// Categories has a matcher per category of the taxonomy, in its order.
+	Categories []CategoryMatcher
+	normalizer Normalizer
+}
+
+type CategoryMatcher struct {
+	Name    string
+	Matcher *TermMatcher
+}
+
+// TermMatcher matches dictionary terms in text. With a normalizer, terms and
+// text are both normalized before matching; without it a trailing "*" of a
+// term is dropped and the term matches as a plain substring. Exclusions are
+// phrases blanked out of the text before matching, e.g. "курский вокзал" that
+// would otherwise match the term of Курская область. It is immutable and safe
+// for concurrent use, so all workers share the same matchers.
+type TermMatcher struct {
+	matcher    *ahocorasick.Matcher
+	normalizer Normalizer
+	terms      []string
+	patterns   []string
+	exclusions []string
+}
+
+// TermLocation is a dictionary term found in a text. Offset is counted in
+// characters of the matched text, which is the normalized text when the
+// matcher has a normalizer.
+type TermLocation struct {
+	Term   string
+	Offset int
+}
+
+func NewTermMatcher(terms []string, normalizer Normalizer) *TermMatcher {
+	return NewExcludingTermMatcher(terms, nil, normalizer)
+}
+
+// NewExcludingTermMatcher returns a matcher of terms that ignores the
+// exclusion phrases. Exclusions are normalized like terms.
+func NewExcludingTermMatcher(terms, exclusions []string, normalizer Normalizer) *TermMatcher {
+	patterns := make([]string, len(terms))
+	for i, term := range terms {
+		patterns[i] = normalizePattern(term, normalizer)
+	}
+	excluded := make([]string, len(exclusions))
+	for i, phrase := range exclusions {
+		excluded[i] = normalizePattern(phrase, normalizer)
+	}
+	return &TermMatcher{
+		matcher:    ahocorasick.NewStringMatcher(patterns),
+		normalizer: normalizer,
+		terms:      terms,
+		patterns:   patterns,
+		exclusions: excluded,
+	}
+}
+
+func normalizePattern(term string, normalizer Normalizer) string {
+	if normalizer != nil {
+		return normalizer.NormalizeTerm(term)
+	}
+	return strings.TrimSuffix(term, "*")
+}
+
+func (t *TermMatcher) Match(text []byte) []int {
+	return t.matcher.MatchThreadSafe([]byte(t.Matched(string(text))))
+}
+
+// Term returns the dictionary term with the given match index.
+func (t *TermMatcher) Term(idx int) string {
+	return t.terms[idx]
+}
+
+// Locate returns where the terms with the given match indices first occur in
+// text.
+func (t *TermMatcher) Locate(text string, matches []int) []TermLocation {
+	text = t.Matched(text)
+	locations := make([]TermLocation, 0, len(matches))
+	for _, idx := range matches {
+		offset := strings.Index(text, t.patterns[idx])
+		if offset < 0 {
+			continue
+		}
+		locations = append(locations, TermLocation{
+			Term:   t.terms[idx],
+			Offset: utf8.RuneCountInString(text[:offset]),
+		})
+	}
+	return locations
+}
+
+// LocateAll returns every occurrence of the terms with the given match
+// indices in text.
+func (t *TermMatcher) LocateAll(text string, matches []int) []TermLocation {
+	text = t.Matched(text)
+	var locations []TermLocation
+	for _, idx := range matches {
+		pattern := t.patterns[idx]
+		for start := 0; pattern != ""; {
+			offset := strings.Index(text[start:], pattern)
+			if offset < 0 {
+				break
+			}
+			locations = append(locations, TermLocation{
+				Term:   t.terms[idx],
+				Offset: utf8.RuneCountInString(text[:start+offset]),
+			})
+			start += offset + len(pattern)
+		}
+	}
+	return locations
+}
+
+// Matched returns text as the matcher sees it, normalized if the matcher has
+// a normalizer and with the exclusions blanked out. Offsets of term locations
+// are counted in this text.
+func (t *TermMatcher) Matched(text string) string {
+	if t.normalizer != nil {
+		text = t.normalizer.NormalizeText(text)
+	}
+	for _, phrase := range t.exclusions {
+		if strings.Contains(text, phrase) {
+			text = strings.ReplaceAll(text, phrase, blank(phrase))
+		}
+	}
+	return text
+}
+
+// blank returns as many spaces as phrase has characters, so that offsets
+// after a blanked phrase do not move.
+func blank(phrase string) string {
+	return strings.Repeat(" ", utf8.RuneCountInString(phrase))
 }
 
-func NewMatcherCreator(dict *Dictionaries, regions []string) MatchersCreator {
+// NewMatcherCreator returns a creator of matchers for dict. normalizer may be
+// nil; it is applied to word dictionaries only, emoji prefixes always match
+// as they are.
+func NewMatcherCreator(dict *Dictionaries, regions []string, normalizer Normalizer) MatchersCreator {
 	return &DefaultMatchersCreator{
-		dict:    dict,
-		regions: regions,
+		dict:       dict,
+		regions:    regions,
+		normalizer: normalizer,
+	}
+}
+
+// literalMatcher returns a matcher of a single literal. Literals without
+// letters, such as emoji, are never normalized.
+func (m Matchers) literalMatcher(literal string) *TermMatcher {
+	if strings.IndexFunc(literal, unicode.IsLetter) < 0 {
+		return NewTermMatcher([]string{literal}, nil)
 	}
+	return NewTermMatcher([]string{literal}, m.normalizer)
+}
+
+// ByName returns the matcher of a dictionary named as in channel rules.
+func (m Matchers) ByName(name string) *TermMatcher {
+	switch name {
+	case DictPrefix:
+		return m.PrefixMatcher
+	case DictPrefixIC:
+		return m.PrefixICMatcher
+	case DictVerbs:
+		return m.VerbMatcher
+	case DictPSK:
+		return m.PSKMatcher
+	case DictErrandBody:
+		return m.ErrandBodyMatcher
+	case DictTypes:
+		return m.ErrandType
+	}
+	return nil
 }
 
 func (m *DefaultMatchersCreator) CreateMatchers() Matchers {
+	categories := make([]CategoryMatcher, 0, len(m.dict.Categories))
+	for _, category := range m.dict.Categories {
+		categories = append(categories, CategoryMatcher{
+			Name:    category.Name,
+			Matcher: NewTermMatcher(category.Terms, m.normalizer),
+		})
+	}
 	return Matchers{
-		PrefixMatcher:     ahocorasick.NewStringMatcher(m.dict.PrefixDictionary),
-		PrefixICMatcher:   ahocorasick.NewStringMatcher(m.dict.PrefixDictionaryIC),
-		VerbMatcher:       ahocorasick.NewStringMatcher(m.dict.VerbsDictionary),
-		PSKMatcher:        ahocorasick.NewStringMatcher(m.dict.PSKDictionary),
-		ErrandBodyMatcher: ahocorasick.NewStringMatcher(m.dict.ErrandBodyDictionary),
-		Regions:           ahocorasick.NewStringMatcher(m.regions),
-		ErrandType:        ahocorasick.NewStringMatcher(m.dict.ErrandTypesDictionary),
+		PrefixMatcher:     NewTermMatcher(m.dict.PrefixDictionary, nil),
+		PrefixICMatcher:   NewTermMatcher(m.dict.PrefixDictionaryIC, nil),
+		VerbMatcher:       NewTermMatcher(m.dict.VerbsDictionary, m.normalizer),
+		PSKMatcher:        NewTermMatcher(m.dict.PSKDictionary, m.normalizer),
+		ErrandBodyMatcher: NewTermMatcher(m.dict.ErrandBodyDictionary, m.normalizer),
+		Regions:           NewExcludingTermMatcher(m.regions, m.dict.PlaceExclusions, m.normalizer),
+		Localities:        NewExcludingTermMatcher(GetLocalityKeys(m.dict.Localities), m.dict.PlaceExclusions, m.normalizer),
+		ErrandType:        NewTermMatcher(m.dict.ErrandTypesDictionary, m.normalizer),
+		Categories:        categories,
+		normalizer:        m.normalizer,
 	}
 }
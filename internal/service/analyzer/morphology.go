@@ -0,0 +1,202 @@
+package analyzer
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Normalizer brings dictionary terms and post texts to a common form before
+// they are matched.
+type Normalizer interface {
+	NormalizeText(text string) string
+	NormalizeTerm(term string) string
+}
+
+// RussianStemmer normalizes text into a sequence of Snowball stems separated
+// by single spaces. Terms are stemmed the same way and bounded by spaces, so a
+// term matches whole words in any inflection. A term ending with "*" keeps its
+// right side open and matches any word starting with its stem.
+type RussianStemmer struct{}
+
+func NewRussianStemmer() Normalizer {
+	return RussianStemmer{}
+}
+
+func (RussianStemmer) NormalizeText(text string) string {
+	words := splitWords(text)
+	var b strings.Builder
+	b.Grow(len(text) + 2)
+	b.WriteByte(' ')
+	for _, word := range words {
+		b.WriteString(StemRussian(word))
+		b.WriteByte(' ')
+	}
+	return b.String()
+}
+
+func (RussianStemmer) NormalizeTerm(term string) string {
+	prefix := strings.HasSuffix(term, "*")
+	words := splitWords(strings.TrimSuffix(term, "*"))
+	stems := make([]string, 0, len(words))
+	for _, word := range words {
+		stems = append(stems, StemRussian(word))
+	}
+	normalized := " " + strings.Join(stems, " ")
+	if !prefix {
+		normalized += " "
+	}
+	return normalized
+}
+
+func splitWords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+var (
+	perfectiveGerund1 = []string{"в", "вши", "вшись"}
+	perfectiveGerund2 = []string{"ив", "ивши", "ившись", "ыв", "ывши", "ывшись"}
+	adjectiveEndings  = []string{"ее", "ие", "ые", "ое", "ими", "ыми", "ей", "ий", "ый", "ой", "ем", "им", "ым", "ом",
+		"его", "ого", "ему", "ому", "их", "ых", "ую", "юю", "ая", "яя", "ою", "ею"}
+	participle1 = []string{"ем", "нн", "вш", "ющ", "щ"}
+	participle2 = []string{"ивш", "ывш", "ующ"}
+	reflexive   = []string{"ся", "сь"}
+	verb1       = []string{"ла", "на", "ете", "йте", "ли", "й", "л", "ем", "н", "ло", "но", "ет", "ют", "ны", "ть", "ешь", "нно"}
+	verb2       = []string{"ила", "ыла", "ена", "ейте", "уйте", "ите", "или", "ыли", "ей", "уй", "ил", "ыл", "им", "ым", "ен",
+		"ило", "ыло", "ено", "ят", "ует", "уют", "ит", "ыт", "ены", "ить", "ыть", "ишь", "ую", "ю"}
+	noun = []string{"а", "ев", "ов", "ие", "ье", "е", "иями", "ями", "ами", "еи", "ии", "и", "ией", "ей", "ой", "ий", "й",
+		"иям", "ям", "ием", "ем", "ам", "ом", "о", "у", "ах", "иях", "ях", "ы", "ь", "ию", "ью", "ю", "ия", "ья", "я"}
+	derivational = []string{"ост", "ость"}
+	superlative  = []string{"ейш", "ейше"}
+)
+
+// StemRussian returns the Snowball stem of a single lower case Russian word.
+func StemRussian(word string) string {
+	w := []rune(strings.ReplaceAll(word, "ё", "е"))
+	rv, r2 := russianRegions(w)
+	if rv >= len(w) {
+		return string(w)
+	}
+
+	// Step 1
+	if stem, ok := removeGrouped(w, rv, perfectiveGerund1, perfectiveGerund2); ok {
+		w = stem
+	} else {
+		if stem, ok := removeEnding(w, rv, reflexive); ok {
+			w = stem
+		}
+		if stem, ok := removeAdjectival(w, rv); ok {
+			w = stem
+		} else if stem, ok := removeGrouped(w, rv, verb1, verb2); ok {
+			w = stem
+		} else if stem, ok := removeEnding(w, rv, noun); ok {
+			w = stem
+		}
+	}
+
+	// Step 2
+	if stem, ok := removeEnding(w, rv, []string{"и"}); ok {
+		w = stem
+	}
+
+	// Step 3
+	if stem, ok := removeEnding(w, max(rv, r2), derivational); ok {
+		w = stem
+	}
+
+	// Step 4
+	switch {
+	case hasEnding(w, rv, superlative) != "":
+		w, _ = removeEnding(w, rv, superlative)
+		if hasEnding(w, rv, []string{"нн"}) != "" {
+			w = w[:len(w)-1]
+		}
+	case hasEnding(w, rv, []string{"нн"}) != "":
+		w = w[:len(w)-1]
+	case hasEnding(w, rv, []string{"ь"}) != "":
+		w = w[:len(w)-1]
+	}
+	return string(w)
+}
+
+func isRussianVowel(r rune) bool {
+	return strings.ContainsRune("аеиоуыэюя", r)
+}
+
+// russianRegions returns the starts of RV and R2.
+func russianRegions(w []rune) (rv, r2 int) {
+	rv, r1, r2 := len(w), len(w), len(w)
+	for i, r := range w {
+		if isRussianVowel(r) {
+			rv = i + 1
+			break
+		}
+	}
+	for i := 1; i < len(w); i++ {
+		if !isRussianVowel(w[i]) && isRussianVowel(w[i-1]) {
+			r1 = i + 1
+			break
+		}
+	}
+	for i := r1 + 1; i < len(w); i++ {
+		if !isRussianVowel(w[i]) && isRussianVowel(w[i-1]) {
+			r2 = i + 1
+			break
+		}
+	}
+	return rv, r2
+}
+
+// hasEnding returns the longest of endings the word ends with inside the
+// region starting at limit.
+func hasEnding(w []rune, limit int, endings []string) string {
+	longest := ""
+	for _, ending := range endings {
+		e := []rune(ending)
+		if len(e) <= len([]rune(longest)) || len(w)-len(e) < limit {
+			continue
+		}
+		if string(w[len(w)-len(e):]) == ending {
+			longest = ending
+		}
+	}
+	return longest
+}
+
+func removeEnding(w []rune, limit int, endings []string) ([]rune, bool) {
+	ending := hasEnding(w, limit, endings)
+	if ending == "" {
+		return w, false
+	}
+	return w[:len(w)-len([]rune(ending))], true
+}
+
+// removeGrouped removes the longest ending of both groups. Endings of the
+// first group are removed only when preceded by "а" or "я".
+func removeGrouped(w []rune, limit int, group1, group2 []string) ([]rune, bool) {
+	e1 := hasEnding(w, limit, group1)
+	e2 := hasEnding(w, limit, group2)
+	if e1 == "" && e2 == "" {
+		return w, false
+	}
+	if len([]rune(e2)) >= len([]rune(e1)) {
+		return w[:len(w)-len([]rune(e2))], true
+	}
+	pos := len(w) - len([]rune(e1))
+	if pos-1 < limit || (w[pos-1] != 'а' && w[pos-1] != 'я') {
+		return w, false
+	}
+	return w[:pos], true
+}
+
+func removeAdjectival(w []rune, limit int) ([]rune, bool) {
+	stem, ok := removeEnding(w, limit, adjectiveEndings)
+	if !ok {
+		return w, false
+	}
+	if withoutParticiple, ok := removeGrouped(stem, limit, participle1, participle2); ok {
+		return withoutParticiple, true
+	}
+	return stem, true
+}
@@ -0,0 +1,103 @@
+package analyzer_test
+
+import (
+	"testing"
+
+	"github.com/ScrpTrx-Go/GoTGParse/internal/service/analyzer"
+)
+
+func TestStemRussian(t *testing.T) {
+	cases := map[string]string{
+		"бесконечности": "бесконечн",
+		"величайшее":    "величайш",
+		"взволнованный": "взволнова",
+		"поручил":       "поруч",
+		"курской":       "курск",
+		"курский":       "курск",
+		"председателю":  "председател",
+		"якутии":        "якут",
+		"красивейшая":   "красив",
+	}
+	for word, want := range cases {
+		if got := analyzer.StemRussian(word); got != want {
+			t.Errorf("StemRussian(%q) = %q, want %q", word, got, want)
+		}
+	}
+}
+
+func TestTermMatcherWordBoundaries(t *testing.T) {
+	stemmer := analyzer.NewRussianStemmer()
+	matcher := analyzer.NewTermMatcher([]string{"брянская область", "поруч*", "тыв*"}, stemmer)
+
+	cases := []struct {
+		text string
+		want int
+	}{
+		{"Следователи Брянской области", 1},
+		{"Председатель СК поручил доложить", 1},
+		{"Поручение Председателя", 1},
+		{"В Республике Тыва", 1},
+		{"Деревня Брянская", 0},
+		{"Подпоручик", 0},
+	}
+	for _, c := range cases {
+		if got := len(matcher.Match([]byte(c.text))); got != c.want {
+			t.Errorf("%q: %d matches, want %d", c.text, got, c.want)
+		}
+	}
+
+	raw := analyzer.NewTermMatcher([]string{"поруч*"}, nil)
+	if len(raw.Match([]byte("подпоручик"))) != 1 {
+		t.Error("raw matcher must match substrings")
+	}
+}
+
+func TestTermMatcherExclusions(t *testing.T) {
+	terms := []string{" курск"}
+	exclusions := []string{"курский вокзал", "курского вокзала", "курском вокзале"}
+	for name, normalizer := range map[string]analyzer.Normalizer{
+		"stemmed": analyzer.NewRussianStemmer(),
+		"raw":     nil,
+	} {
+		matcher := analyzer.NewExcludingTermMatcher(terms, exclusions, normalizer)
+		cases := []struct {
+			text string
+			want int
+		}{
+			{"задержан на курском вокзале", 0},
+			{"у курского вокзала в москве", 0},
+			{"в курской области", 1},
+			{"на курском вокзале задержан житель курской области", 1},
+		}
+		for _, c := range cases {
+			if got := len(matcher.Match([]byte(c.text))); got != c.want {
+				t.Errorf("%s: %q: %d matches, want %d", name, c.text, got, c.want)
+			}
+		}
+	}
+
+	stemmed := analyzer.NewExcludingTermMatcher(terms, exclusions, analyzer.NewRussianStemmer())
+	text := "на Курском вокзале задержан житель Курской области"
+	locations := stemmed.Locate(text, stemmed.Match([]byte(text)))
+	if len(locations) != 1 || []rune(stemmed.Matched(text))[locations[0].Offset+1] != 'к' {
+		t.Errorf("exclusions must keep offsets, got %+v in %q", locations, stemmed.Matched(text))
+	}
+}
+
+func TestTermMatcherWholeWordStem(t *testing.T) {
+	matcher := analyzer.NewTermMatcher([]string{" курск"}, analyzer.NewRussianStemmer())
+	if len(matcher.Match([]byte("Курскэнерго"))) != 0 {
+		t.Error("a term without \"*\" must not match the start of a longer word")
+	}
+	if len(matcher.Match([]byte("Курская область"))) != 1 {
+		t.Error("a term without \"*\" must match its word in any form")
+	}
+}
+
+func TestTermMatcherRepeatedTerm(t *testing.T) {
+	// Equal terms share one pattern, so a repeated term matches once.
+	matcher := analyzer.NewTermMatcher([]string{"бастрыкин", "поруч*", "бастрыкин"}, nil)
+	if got := len(matcher.Match([]byte("бастрыкин поручил"))); got != 2 {
+		t.Errorf("%d matches, want 2", got)
+	}
+}
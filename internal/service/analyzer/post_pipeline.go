@@ -4,6 +4,7 @@ import (
 	"context"
 	"sync"
 
+	"github.com/ScrpTrx-Go/GoTGParse/internal/config"
 	"github.com/ScrpTrx-Go/GoTGParse/internal/domain/model"
 	pkg "github.com/ScrpTrx-Go/GoTGParse/pkg/logger"
 )
@@ -11,31 +12,67 @@ import (
 type PostPipeline struct {
 	Log     pkg.Logger
 	Workers []AnalyzePostWorker
+	Stages  []PostStage
+	Output  config.AnalyzerOutput
 }
 
-func NewPostPipeline(log pkg.Logger, workers []AnalyzePostWorker) *PostPipeline {
+func NewPostPipeline(log pkg.Logger, workers []AnalyzePostWorker, output config.AnalyzerOutput, stages ...PostStage) *PostPipeline {
 	return &PostPipeline{
 		Log:     log,
 		Workers: workers,
+		Stages:  stages,
+		Output:  output,
 	}
 }
 
-func (p *PostPipeline) RunAnalyzePipeline(ctx context.Context, in <-chan *model.Post) <-chan *model.Post {
-	out := make(chan *model.Post)
+// RunAnalyzePipeline analyzes the posts of in with all workers. The returned
+// stats sum up the workers and are complete once the output channel is
+// closed. With Output.Ordered the posts of each channel are put back in the
+// order they were fetched in before the stages run.
+func (p *PostPipeline) RunAnalyzePipeline(ctx context.Context, in <-chan *model.Post) (<-chan *model.Post, *model.AnalysisStats) {
+	out := make(chan *model.Post, p.Output.Buffer)
+	stats := model.NewAnalysisStats()
 	var wg sync.WaitGroup
 
 	for _, worker := range p.Workers {
 		wg.Add(1)
 		go func(w AnalyzePostWorker) {
 			defer wg.Done()
-			w.Run(ctx, in, out)
+			w.Run(ctx, in, out, stats)
 		}(worker)
 	}
 
 	go func() {
 		wg.Wait()
+		stats.Finish()
 		close(out)
 	}()
 
+	var analyzed <-chan *model.Post = out
+	if p.Output.Ordered {
+		analyzed = p.runReorder(ctx, analyzed)
+	}
+	if len(p.Stages) == 0 {
+		return analyzed, stats
+	}
+	return p.runStages(ctx, analyzed), stats
+}
+
+// runStages passes the analyzed posts through the stages in order.
+func (p *PostPipeline) runStages(ctx context.Context, in <-chan *model.Post) <-chan *model.Post {
+	out := make(chan *model.Post, p.Output.Buffer)
+	go func() {
+		defer close(out)
+		for post := range in {
+			for _, stage := range p.Stages {
+				stage.Process(post)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case out <- post:
+			}
+		}
+	}()
 	return out
 }
@@ -0,0 +1,178 @@
+package analyzer_test
+
+import (
+	"context"
+	"math/rand/v2"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ScrpTrx-Go/GoTGParse/internal/config"
+	"github.com/ScrpTrx-Go/GoTGParse/internal/domain/model"
+	"github.com/ScrpTrx-Go/GoTGParse/internal/service/analyzer"
+	pkg "github.com/ScrpTrx-Go/GoTGParse/pkg/logger"
+)
+
+func newTestWorkers(t *testing.T, count int) (pkg.Logger, []analyzer.AnalyzePostWorker) {
+	t.Helper()
+	log, err := pkg.NewZapLogger(config.LoggerConfig{Level: "error", FilePath: filepath.Join(t.TempDir(), "app.log")})
+	if err != nil {
+		t.Fatalf("NewZapLogger: %v", err)
+	}
+	rules, err := analyzer.NewChannelRules([]config.ChannelRule{{
+		Username:      "sledcom_press",
+		Detector:      analyzer.DetectorTitle,
+		Dictionaries:  []string{analyzer.DictPrefix, analyzer.DictVerbs, analyzer.DictPSK},
+		ReportSection: config.SectionSledcom,
+	}})
+	if err != nil {
+		t.Fatalf("NewChannelRules: %v", err)
+	}
+	return log, analyzer.NewAnalyzeWorkers(count, log, analyzer.NewDictionariesCreator().CreateDictionaries(), rules, nil, nil, 0.5)
+}
+
+func TestPostPipelineSharedEngine(t *testing.T) {
+	log, workers := newTestWorkers(t, 4)
+
+	const posts = 200
+	in := make(chan *model.Post)
+	go func() {
+		defer close(in)
+		for i := 0; i < posts; i++ {
+			text := "Новости дня"
+			if i%2 == 0 {
+				text = "❗️Председатель СК поручил доложить\nРуководителю следственного управления по Самарской области поручено доложить"
+			}
+			in <- &model.Post{ID: int64(i), Username: "sledcom_press", Text: text}
+		}
+	}()
+
+	out, stats := analyzer.NewPostPipeline(log, workers, config.AnalyzerOutput{}).RunAnalyzePipeline(context.Background(), in)
+	errands := 0
+	for post := range out {
+		if post.IsErrand {
+			errands++
+			if len(post.Regions) != 1 || post.Regions[0] != "Самарская область" {
+				t.Errorf("post %d regions = %v", post.ID, post.Regions)
+			}
+		}
+	}
+
+	if errands != posts/2 {
+		t.Errorf("errands = %d, want %d", errands, posts/2)
+	}
+	totals := stats.Totals()
+	if totals.Errands != posts/2 || totals.Skipped != posts/2 {
+		t.Errorf("totals = %+v, want %d errands and %d skipped", totals, posts/2, posts/2)
+	}
+	if n := len(stats.Workers()); n != 4 {
+		t.Errorf("worker stats = %d, want 4", n)
+	}
+	if channels := stats.Channels(); len(channels) != 1 || channels[0].Errands != posts/2 {
+		t.Errorf("channels = %+v", channels)
+	}
+}
+
+// shuffledFetch sends perChannel posts of every channel the way the fetcher
+// does: numbered newest first, then finished by link workers in any order.
+func shuffledFetch(perChannel int, usernames ...string) <-chan *model.Post {
+	start := time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)
+	numbered := make(chan *model.Post)
+	go func() {
+		defer close(numbered)
+		for i := perChannel - 1; i >= 0; i-- {
+			for _, username := range usernames {
+				numbered <- &model.Post{
+					ID:        int64(i),
+					Username:  username,
+					Text:      "Новости дня",
+					Timestamp: start.Add(time.Duration(i) * time.Minute),
+					Sequence:  uint64(perChannel - i),
+				}
+			}
+		}
+	}()
+
+	out := make(chan *model.Post)
+	var wg sync.WaitGroup
+	for range 5 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for post := range numbered {
+				time.Sleep(time.Duration(rand.IntN(200)) * time.Microsecond)
+				out <- post
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+	return out
+}
+
+func TestPostPipelineOrdered(t *testing.T) {
+	log, workers := newTestWorkers(t, 4)
+
+	// Far more posts per channel than the window holds.
+	const perChannel = 1000
+	in := shuffledFetch(perChannel, "sledcom_press", "infocentrskrf")
+
+	output := config.AnalyzerOutput{Buffer: 8, Ordered: true, ReorderWindow: 256}
+	out, _ := analyzer.NewPostPipeline(log, workers, output).RunAnalyzePipeline(context.Background(), in)
+	next := map[string]int64{"sledcom_press": perChannel - 1, "infocentrskrf": perChannel - 1}
+	for post := range out {
+		if post.ID != next[post.Username] {
+			t.Fatalf("%s: got post %d, want %d", post.Username, post.ID, next[post.Username])
+		}
+		next[post.Username]--
+	}
+	for _, username := range []string{"sledcom_press", "infocentrskrf"} {
+		if next[username] != -1 {
+			t.Errorf("%s: stopped before post %d", username, next[username])
+		}
+	}
+}
+
+func TestPostPipelineOrderedSmallWindow(t *testing.T) {
+	log, workers := newTestWorkers(t, 2)
+
+	// A window smaller than the disorder cannot restore the order, but no
+	// post may be lost.
+	const posts = 300
+	in := shuffledFetch(posts, "sledcom_press")
+	output := config.AnalyzerOutput{Ordered: true, ReorderWindow: 1}
+	out, _ := analyzer.NewPostPipeline(log, workers, output).RunAnalyzePipeline(context.Background(), in)
+	seen := map[int64]bool{}
+	for post := range out {
+		seen[post.ID] = true
+	}
+	if len(seen) != posts {
+		t.Errorf("got %d posts, want %d", len(seen), posts)
+	}
+}
+
+func TestPostPipelineOrderedUnnumbered(t *testing.T) {
+	log, workers := newTestWorkers(t, 2)
+
+	// Stored posts have no sequence number and pass through as they come.
+	const posts = 50
+	in := make(chan *model.Post)
+	go func() {
+		defer close(in)
+		for i := range posts {
+			in <- &model.Post{ID: int64(i), Username: "sledcom_press", Text: "Новости дня"}
+		}
+	}()
+	output := config.AnalyzerOutput{Ordered: true, ReorderWindow: 8}
+	out, _ := analyzer.NewPostPipeline(log, workers, output).RunAnalyzePipeline(context.Background(), in)
+	count := 0
+	for range out {
+		count++
+	}
+	if count != posts {
+		t.Errorf("got %d posts, want %d", count, posts)
+	}
+}
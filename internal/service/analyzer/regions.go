@@ -0,0 +1,110 @@
+package analyzer
+
+import (
+	"slices"
+	"strings"
+)
+
+// Weights of a region mention. A mention right after "в" or "по" names where
+// the incident happened or which unit investigates it, a mention near an
+// errand verb names the addressee, while "уроженец Курской области" only
+// tells where someone comes from. Earlier mentions weigh more.
+const (
+	scoreMention     = 1.0
+	scorePreposition = 1.0
+	scoreNearVerb    = 1.0
+	scorePosition    = 1.0
+	scoreOrigin      = -2.0
+
+	// verbDistance is how many characters from an errand verb a mention
+	// counts as near it.
+	verbDistance = 80
+)
+
+// locationPrepositions precede a region that the errand is about.
+var locationPrepositions = []string{"в", "во", "по"}
+
+// originWords precede a region that is only mentioned as someone's origin.
+// They are compared by prefix so that normalized text matches as well.
+var originWords = []string{"урожен", "родом", "прибы", "приеха", "переех"}
+
+// regionScores weighs the mentions of every candidate region in text, the
+// text that matches produced.
+func (e *Engine) regionScores(text string, regionMatches, localityMatches []int) map[string]float64 {
+	matched := []rune(e.matchers.Regions.Matched(text))
+	var verbs []int
+	for _, loc := range e.matchers.VerbMatcher.LocateAll(text, e.matchers.VerbMatcher.Match([]byte(text))) {
+		verbs = append(verbs, loc.Offset)
+	}
+
+	scores := make(map[string]float64)
+	mention := func(region string, offset int) {
+		score := scoreMention + scorePosition*(1-float64(offset)/float64(max(1, len(matched))))
+		before := strings.Fields(strings.ToLower(string(matched[:min(offset, len(matched))])))
+		if len(before) > 0 {
+			last := before[len(before)-1]
+			if slices.Contains(locationPrepositions, last) {
+				score += scorePreposition
+			}
+			for _, word := range before[max(0, len(before)-2):] {
+				if word == "из" || hasAnyPrefix(word, originWords) {
+					score += scoreOrigin
+					break
+				}
+			}
+		}
+		for _, verb := range verbs {
+			if abs(verb-offset) <= verbDistance {
+				score += scoreNearVerb
+				break
+			}
+		}
+		scores[region] += max(0, score)
+	}
+	for _, loc := range e.matchers.Regions.LocateAll(text, regionMatches) {
+		mention(e.dict.RegionsAllias[loc.Term], loc.Offset)
+	}
+	for _, loc := range e.matchers.Localities.LocateAll(text, localityMatches) {
+		if locality, ok := e.dict.Localities[loc.Term]; ok {
+			mention(locality.Region, loc.Offset)
+		}
+	}
+	return scores
+}
+
+// primaryRegion returns the candidate with the highest score, the other
+// candidates in the order of their scores, and how clearly the primary one
+// wins: 1 if no other candidate has a score, 0 on a tie.
+func primaryRegion(candidates []string, scores map[string]float64) (string, []string, float64) {
+	ranked := slices.Clone(candidates)
+	slices.SortStableFunc(ranked, func(a, b string) int {
+		switch {
+		case scores[a] > scores[b]:
+			return -1
+		case scores[a] < scores[b]:
+			return 1
+		}
+		return 0
+	})
+	top := scores[ranked[0]]
+	if top <= 0 {
+		return ranked[0], ranked[1:], 0
+	}
+	return ranked[0], ranked[1:], (top - scores[ranked[1]]) / top
+}
+
+func hasAnyPrefix(word string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(word, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
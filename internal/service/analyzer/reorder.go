@@ -0,0 +1,126 @@
+package analyzer
+
+import (
+	"container/heap"
+	"context"
+
+	"github.com/ScrpTrx-Go/GoTGParse/internal/domain/model"
+)
+
+// seqHeap keeps the posts of one channel, the earliest fetched on top.
+type seqHeap []*model.Post
+
+func (h seqHeap) Len() int { return len(h) }
+
+func (h seqHeap) Less(i, j int) bool { return h[i].Sequence < h[j].Sequence }
+
+func (h seqHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *seqHeap) Push(x any) { *h = append(*h, x.(*model.Post)) }
+
+func (h *seqHeap) Pop() any {
+	old := *h
+	post := old[len(old)-1]
+	old[len(old)-1] = nil
+	*h = old[:len(old)-1]
+	return post
+}
+
+// reorderer releases the posts of each channel in the order of their
+// model.Post.Sequence. It holds back up to window posts per channel while
+// waiting for a missing one; when the window is full, the earliest held post
+// is released and the missing one is passed on late, as is.
+type reorderer struct {
+	window   int
+	pending  map[string]*seqHeap
+	released map[string]uint64
+	late     int
+}
+
+func newReorderer(window int) *reorderer {
+	return &reorderer{
+		window:   max(window, 1),
+		pending:  make(map[string]*seqHeap),
+		released: make(map[string]uint64),
+	}
+}
+
+// Add takes a post and returns the posts to release, in order. Posts without
+// a sequence number are released at once.
+func (r *reorderer) Add(post *model.Post) []*model.Post {
+	if post.Sequence == 0 {
+		return []*model.Post{post}
+	}
+	username := post.Username
+	if post.Sequence < r.next(username) {
+		r.late++
+		return []*model.Post{post}
+	}
+	h := r.pending[username]
+	if h == nil {
+		h = &seqHeap{}
+		r.pending[username] = h
+	}
+	heap.Push(h, post)
+
+	var ready []*model.Post
+	for h.Len() > 0 && ((*h)[0].Sequence == r.next(username) || h.Len() > r.window) {
+		ready = append(ready, r.release(username))
+	}
+	return ready
+}
+
+// Flush returns all held back posts, each channel in order.
+func (r *reorderer) Flush() []*model.Post {
+	var posts []*model.Post
+	for username, h := range r.pending {
+		for h.Len() > 0 {
+			posts = append(posts, r.release(username))
+		}
+	}
+	return posts
+}
+
+func (r *reorderer) release(username string) *model.Post {
+	post := heap.Pop(r.pending[username]).(*model.Post)
+	r.released[username] = post.Sequence
+	return post
+}
+
+// next returns the sequence number of the post of the channel to release
+// next.
+func (r *reorderer) next(username string) uint64 {
+	return r.released[username] + 1
+}
+
+// runReorder restores the order in which the posts of each channel were
+// fetched, see config.AnalyzerOutput.
+func (p *PostPipeline) runReorder(ctx context.Context, in <-chan *model.Post) <-chan *model.Post {
+	out := make(chan *model.Post, p.Output.Buffer)
+	go func() {
+		defer close(out)
+		r := newReorderer(p.Output.ReorderWindow)
+		send := func(posts []*model.Post) bool {
+			for _, post := range posts {
+				select {
+				case <-ctx.Done():
+					return false
+				case out <- post:
+				}
+			}
+			return true
+		}
+		for post := range in {
+			if !send(r.Add(post)) {
+				return
+			}
+		}
+		if !send(r.Flush()) {
+			return
+		}
+		if r.late > 0 {
+			p.Log.Warn("Posts released out of order, the reorder window is too small", "count", r.late, "window", r.window)
+		}
+	}()
+	return out
+}
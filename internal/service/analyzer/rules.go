@@ -0,0 +1,120 @@
+package analyzer
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ScrpTrx-Go/GoTGParse/internal/config"
+)
+
+const (
+	DetectorTitle      = "title"
+	DetectorPrefix     = "prefix"
+	DetectorExpression = "expression"
+)
+
+// Names of the dictionaries that channel rules may refer to.
+const (
+	DictPrefix     = "prefix"
+	DictPrefixIC   = "prefix_ic"
+	DictVerbs      = "verbs"
+	DictPSK        = "psk"
+	DictErrandBody = "errand_body"
+	DictTypes      = "types"
+)
+
+// ChannelRules maps a channel username to its classification rule.
+type ChannelRules map[string]*ChannelRule
+
+type ChannelRule struct {
+	config.ChannelRule
+	expressions []namedExpression
+}
+
+type namedExpression struct {
+	name  string
+	maybe bool
+	expr  *Expression
+}
+
+func NewChannelRules(rules []config.ChannelRule) (ChannelRules, error) {
+	result := make(ChannelRules, len(rules))
+	var errs []error
+	for i, cfgRule := range rules {
+		rule := &ChannelRule{ChannelRule: cfgRule}
+		if rule.Username == "" {
+			errs = append(errs, fmt.Errorf("channels[%d]: empty username", i))
+			continue
+		}
+		if _, ok := result[rule.Username]; ok {
+			errs = append(errs, fmt.Errorf("channels[%d]: duplicate rule for %q", i, rule.Username))
+		}
+		if len(rule.Dictionaries) == 0 && rule.Detector != DetectorExpression {
+			errs = append(errs, fmt.Errorf("channels[%d]: no dictionaries for %q", i, rule.Username))
+		}
+		for _, name := range rule.Dictionaries {
+			if !isDictionaryName(name) {
+				errs = append(errs, fmt.Errorf("channels[%d]: unknown dictionary %q", i, name))
+			}
+		}
+		if rule.ReportSection != config.SectionSledcom && rule.ReportSection != config.SectionIC {
+			errs = append(errs, fmt.Errorf("channels[%d]: unknown report section %q", i, rule.ReportSection))
+		}
+		switch rule.Detector {
+		case DetectorTitle:
+			if rule.MinMatches <= 0 || rule.MinMatches > len(rule.Dictionaries) {
+				rule.MinMatches = len(rule.Dictionaries)
+			}
+			if rule.MaybeMatches <= 0 || rule.MaybeMatches > rule.MinMatches {
+				rule.MaybeMatches = rule.MinMatches
+			}
+		case DetectorPrefix:
+		case DetectorExpression:
+			if len(rule.Expressions) == 0 {
+				errs = append(errs, fmt.Errorf("channels[%d]: no expressions for %q", i, rule.Username))
+			}
+			for j, exprRule := range rule.Expressions {
+				expr, err := ParseExpression(exprRule.When)
+				if err != nil {
+					errs = append(errs, fmt.Errorf("channels[%d].expressions[%d]: %w", i, j, err))
+					continue
+				}
+				name := exprRule.Name
+				if name == "" {
+					name = fmt.Sprintf("%s#%d", rule.Username, j)
+				}
+				rule.expressions = append(rule.expressions, namedExpression{
+					name:  name,
+					maybe: exprRule.Maybe,
+					expr:  expr,
+				})
+			}
+		default:
+			errs = append(errs, fmt.Errorf("channels[%d]: unknown detector %q", i, rule.Detector))
+		}
+		result[rule.Username] = rule
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// literals returns the literals used by the expressions of all rules.
+func (r ChannelRules) literals() []string {
+	var literals []string
+	for _, rule := range r {
+		for _, named := range rule.expressions {
+			literals = append(literals, named.expr.Literals()...)
+		}
+	}
+	return literals
+}
+
+func isDictionaryName(name string) bool {
+	switch name {
+	case DictPrefix, DictPrefixIC, DictVerbs, DictPSK, DictErrandBody, DictTypes:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,77 @@
+package analyzer_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ScrpTrx-Go/GoTGParse/internal/config"
+	"github.com/ScrpTrx-Go/GoTGParse/internal/service/analyzer"
+)
+
+func TestNewChannelRules(t *testing.T) {
+	rules, err := analyzer.NewChannelRules([]config.ChannelRule{
+		{
+			Username:      "sledcom_press",
+			Detector:      analyzer.DetectorTitle,
+			Dictionaries:  []string{analyzer.DictPrefix, analyzer.DictVerbs, analyzer.DictPSK},
+			MinMatches:    5,
+			MaybeMatches:  4,
+			ReportSection: config.SectionSledcom,
+		},
+		{
+			Username:      "infocentrskrf",
+			Detector:      analyzer.DetectorPrefix,
+			Dictionaries:  []string{analyzer.DictPrefixIC},
+			ReportSection: config.SectionIC,
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewChannelRules: %v", err)
+	}
+	if len(rules) != 2 {
+		t.Fatalf("got %d rules, want 2", len(rules))
+	}
+	title := rules["sledcom_press"]
+	if title.MinMatches != 3 || title.MaybeMatches != 3 {
+		t.Errorf("title thresholds = %d/%d, want them clamped to 3/3", title.MinMatches, title.MaybeMatches)
+	}
+}
+
+func TestNewChannelRulesErrors(t *testing.T) {
+	valid := config.ChannelRule{
+		Username:      "sledcom_press",
+		Detector:      analyzer.DetectorPrefix,
+		Dictionaries:  []string{analyzer.DictPrefix},
+		ReportSection: config.SectionSledcom,
+	}
+	cases := []struct {
+		name   string
+		modify func(*config.ChannelRule)
+		want   string
+	}{
+		{"empty username", func(r *config.ChannelRule) { r.Username = "" }, "empty username"},
+		{"no dictionaries", func(r *config.ChannelRule) { r.Dictionaries = nil }, "no dictionaries"},
+		{"unknown dictionary", func(r *config.ChannelRule) { r.Dictionaries = []string{"regions"} }, `unknown dictionary "regions"`},
+		{"unknown detector", func(r *config.ChannelRule) { r.Detector = "regexp" }, `unknown detector "regexp"`},
+		{"no report section", func(r *config.ChannelRule) { r.ReportSection = "" }, `unknown report section ""`},
+		{"unknown report section", func(r *config.ChannelRule) { r.ReportSection = "sled" }, `unknown report section "sled"`},
+		{"no expressions", func(r *config.ChannelRule) { r.Detector = analyzer.DetectorExpression }, "no expressions"},
+		{"bad expression", func(r *config.ChannelRule) {
+			r.Detector = analyzer.DetectorExpression
+			r.Expressions = []config.ExpressionRule{{When: "prefix AND"}}
+		}, "channels[0].expressions[0]"},
+	}
+	for _, c := range cases {
+		rule := valid
+		c.modify(&rule)
+		_, err := analyzer.NewChannelRules([]config.ChannelRule{rule})
+		if err == nil || !strings.Contains(err.Error(), c.want) {
+			t.Errorf("%s: err = %v, want it to contain %q", c.name, err, c.want)
+		}
+	}
+
+	_, err := analyzer.NewChannelRules([]config.ChannelRule{valid, valid})
+	if err == nil || !strings.Contains(err.Error(), "duplicate rule") {
+		t.Errorf("duplicate: err = %v, want a duplicate rule error", err)
+	}
+}
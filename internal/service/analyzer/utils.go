@@ -1,5 +1,11 @@
 package analyzer
 
+import (
+	"slices"
+
+	"github.com/ScrpTrx-Go/GoTGParse/internal/domain/model"
+)
+
 func GetRegionKeys(regionsMap map[string]string) []string {
 	regions := make([]string, 0, len(regionsMap))
 	for region := range regionsMap {
@@ -7,3 +13,13 @@ func GetRegionKeys(regionsMap map[string]string) []string {
 	}
 	return regions
 }
+
+// GetLocalityKeys returns the gazetteer terms in a stable order.
+func GetLocalityKeys(localities map[string]model.Locality) []string {
+	keys := make([]string, 0, len(localities))
+	for key := range localities {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
@@ -0,0 +1,78 @@
+package analyzer
+
+import (
+	"context"
+	"os"
+	"time"
+
+	pkg "github.com/ScrpTrx-Go/GoTGParse/pkg/logger"
+)
+
+// DictionaryWatcher polls the dictionary bundle and reloads the workers when
+// the file changes. An invalid bundle is reported and the workers keep the
+// dictionaries they have.
+type DictionaryWatcher struct {
+	path       string
+	interval   time.Duration
+	log        pkg.Logger
+	workers    []AnalyzePostWorker
+	rules      ChannelRules
+	normalizer Normalizer
+	modTime    time.Time
+}
+
+func NewDictionaryWatcher(path string, interval time.Duration, log pkg.Logger, workers []AnalyzePostWorker, rules ChannelRules, normalizer Normalizer) *DictionaryWatcher {
+	return &DictionaryWatcher{
+		path:       path,
+		interval:   interval,
+		log:        log,
+		workers:    workers,
+		rules:      rules,
+		normalizer: normalizer,
+	}
+}
+
+func (w *DictionaryWatcher) Run(ctx context.Context) {
+	if w.path == "" || w.interval <= 0 {
+		return
+	}
+	if info, err := os.Stat(w.path); err == nil {
+		w.modTime = info.ModTime()
+	}
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.check()
+		}
+	}
+}
+
+func (w *DictionaryWatcher) check() {
+	info, err := os.Stat(w.path)
+	if err != nil {
+		w.log.Warn("Failed to stat dictionaries", "path", w.path, "err", err)
+		return
+	}
+	if info.ModTime().Equal(w.modTime) {
+		return
+	}
+	w.modTime = info.ModTime()
+
+	dict, err := LoadDictionaries(w.path)
+	if err != nil {
+		w.log.Error("Dictionaries changed but are invalid, keeping current", "path", w.path, "err", err)
+		return
+	}
+
+	regions := GetRegionKeys(dict.RegionsAllias)
+	engine := NewEngine(NewMatcherCreator(dict, regions, w.normalizer), regions, *dict, w.rules)
+	for _, worker := range w.workers {
+		worker.Reload(engine)
+	}
+	w.log.Info("Dictionaries reloaded", "path", w.path, "version", dict.Version, "workers", len(w.workers))
+}
@@ -0,0 +1,181 @@
+package classifier
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"github.com/ScrpTrx-Go/GoTGParse/internal/config"
+	"github.com/ScrpTrx-Go/GoTGParse/internal/domain/model"
+	"github.com/ScrpTrx-Go/GoTGParse/internal/service/analyzer"
+)
+
+// modelVersion is the version of the model file format.
+const modelVersion = 1
+
+// Classes of the model.
+const (
+	other = iota
+	errand
+)
+
+// NaiveBayes is a multinomial naive Bayes classifier over character n-grams
+// of the lowered text. Character n-grams need no tokenizer and are robust to
+// inflection and typos, so it catches errands worded differently from the
+// dictionaries, e.g. without the emoji prefix.
+type NaiveBayes struct {
+	Version int `json:"version"`
+	MinN    int `json:"min_n"`
+	MaxN    int `json:"max_n"`
+	// Docs and Totals count the training texts and their n-grams by class.
+	Docs   [2]int            `json:"docs"`
+	Totals [2]int            `json:"totals"`
+	Grams  map[string][2]int `json:"grams"`
+}
+
+func NewNaiveBayes(minN, maxN int) *NaiveBayes {
+	return &NaiveBayes{
+		Version: modelVersion,
+		MinN:    minN,
+		MaxN:    maxN,
+		Grams:   make(map[string][2]int),
+	}
+}
+
+// ValidateNGrams checks the n-gram lengths of a model; Load rejects models
+// that do not pass it.
+func ValidateNGrams(minN, maxN int) error {
+	if minN <= 0 || maxN < minN {
+		return fmt.Errorf("invalid n-gram lengths %d..%d: need 0 < min <= max", minN, maxN)
+	}
+	return nil
+}
+
+// Train adds a labeled text to the model.
+func (m *NaiveBayes) Train(text string, isErrand bool) {
+	class := other
+	if isErrand {
+		class = errand
+	}
+	m.Docs[class]++
+	for _, gram := range m.grams(text) {
+		counts := m.Grams[gram]
+		counts[class]++
+		m.Grams[gram] = counts
+		m.Totals[class]++
+	}
+}
+
+// Probability returns the posterior probability that text is an errand. An
+// untrained model returns 0.5.
+func (m *NaiveBayes) Probability(text string) float64 {
+	if m.Docs[other] == 0 || m.Docs[errand] == 0 {
+		return 0.5
+	}
+	docs := float64(m.Docs[other] + m.Docs[errand])
+	vocabulary := float64(len(m.Grams))
+	var score [2]float64
+	for class := range score {
+		score[class] = math.Log(float64(m.Docs[class]) / docs)
+	}
+	for _, gram := range m.grams(text) {
+		counts := m.Grams[gram]
+		for class := range score {
+			// Laplace smoothing keeps unseen n-grams from zeroing a class.
+			score[class] += math.Log((float64(counts[class]) + 1) / (float64(m.Totals[class]) + vocabulary))
+		}
+	}
+	return 1 / (1 + math.Exp(score[other]-score[errand]))
+}
+
+func (m *NaiveBayes) grams(text string) []string {
+	runes := []rune(" " + normalize(text) + " ")
+	var grams []string
+	for n := m.MinN; n <= m.MaxN; n++ {
+		for i := 0; i+n <= len(runes); i++ {
+			grams = append(grams, string(runes[i:i+n]))
+		}
+	}
+	return grams
+}
+
+// normalize lowers the text and collapses all whitespace into single spaces.
+func normalize(text string) string {
+	text = strings.ReplaceAll(strings.ToLower(text), "ё", "е")
+	return strings.Join(strings.FieldsFunc(text, unicode.IsSpace), " ")
+}
+
+// Save writes the model as JSON, creating the directory if needed.
+func (m *NaiveBayes) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+func Load(path string) (*NaiveBayes, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var m NaiveBayes
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("parse model %s: %w", path, err)
+	}
+	if m.Version != modelVersion {
+		return nil, fmt.Errorf("model %s has version %d, want %d", path, m.Version, modelVersion)
+	}
+	if ValidateNGrams(m.MinN, m.MaxN) != nil || m.Grams == nil {
+		return nil, errors.New("model " + path + " is incomplete")
+	}
+	return &m, nil
+}
+
+// NewPolicy loads the model configured in cfg and returns how the analyzer
+// should use it, or nil if the classifier is disabled.
+func NewPolicy(cfg config.ClassifierConfig) (*analyzer.ClassifierPolicy, error) {
+	if cfg.Mode == "" {
+		return nil, nil
+	}
+	bayes, err := Load(cfg.ModelPath)
+	if err != nil {
+		return nil, err
+	}
+	return analyzer.NewClassifierPolicy(bayes, cfg.Mode, cfg.Threshold)
+}
+
+// TrainPosts adds stored posts to the model and returns how many were taken
+// as errands and as other posts. Reviewed posts are labeled by the review.
+// Other posts are labeled by the stored analysis only if it is sure: errands
+// with at least the report confidence and posts no detector accepted.
+func (m *NaiveBayes) TrainPosts(posts []*model.Post, confidence config.Confidence) (errands, others int) {
+	for _, post := range posts {
+		var isErrand bool
+		switch {
+		case post.Review != nil:
+			isErrand = post.Review.Errand
+		case post.IsErrand && post.ErrandConfidence >= confidence.Report:
+			isErrand = true
+		case post.ErrandConfidence == 0:
+			isErrand = false
+		default:
+			continue
+		}
+		m.Train(post.Text, isErrand)
+		if isErrand {
+			errands++
+		} else {
+			others++
+		}
+	}
+	return errands, others
+}
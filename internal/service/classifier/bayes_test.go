@@ -0,0 +1,115 @@
+package classifier
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/ScrpTrx-Go/GoTGParse/internal/domain/model"
+	"github.com/ScrpTrx-Go/GoTGParse/internal/service/analyzer"
+)
+
+var training = []struct {
+	text   string
+	errand bool
+}{
+	{"❗️Председатель СК России поручил доложить о ходе проверки по факту травмирования ребенка", true},
+	{"❗️Александр Бастрыкин поручил возбудить уголовное дело после нападения на подростка", true},
+	{"❗️Глава СК поручил провести проверку по обращению жителей аварийного дома", true},
+	{"Председатель СК потребовал доложить о расследовании гибели рабочего", true},
+	{"В Москве прошло совещание о взаимодействии следственных органов", false},
+	{"Поздравляем сотрудников с профессиональным праздником", false},
+	{"Следователи задержали подозреваемого в краже автомобиля", false},
+	{"Состоялся турнир по мини-футболу среди курсантов академии", false},
+}
+
+func trained() *NaiveBayes {
+	bayes := NewNaiveBayes(2, 4)
+	for _, sample := range training {
+		bayes.Train(sample.text, sample.errand)
+	}
+	return bayes
+}
+
+func TestNaiveBayes(t *testing.T) {
+	bayes := trained()
+	// No emoji prefix, so the title detector would miss it.
+	if p := bayes.Probability("Председатель СК поручил доложить о нападении на пенсионерку"); p < 0.9 {
+		t.Errorf("probability of an errand = %v, want at least 0.9", p)
+	}
+	if p := bayes.Probability("Сотрудники провели турнир и совещание"); p > 0.1 {
+		t.Errorf("probability of another post = %v, want at most 0.1", p)
+	}
+
+	path := filepath.Join(t.TempDir(), "models", "errands.json")
+	if err := bayes.Save(path); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	text := "Бастрыкин поручил провести проверку"
+	if loaded.Probability(text) != bayes.Probability(text) {
+		t.Errorf("loaded model differs from the saved one")
+	}
+}
+
+func TestPolicy(t *testing.T) {
+	bayes := trained()
+	errand := &model.Post{Text: "Председатель СК поручил доложить о нападении на пенсионерку", Trace: &model.AnalysisTrace{}}
+
+	assist, err := analyzer.NewClassifierPolicy(bayes, analyzer.ClassifierAssist, 0.9)
+	if err != nil {
+		t.Fatalf("NewClassifierPolicy: %v", err)
+	}
+	if c := assist.Confidence(errand, 0); c != 0.5 || errand.MatchedRule != "classifier" || errand.Trace.Classifier == nil {
+		t.Errorf("assist confidence = %v, rule %q, want 0.5 by classifier", c, errand.MatchedRule)
+	}
+	if c := assist.Confidence(&model.Post{Text: "Сотрудники провели турнир"}, 1); c != 1 {
+		t.Errorf("assist confidence = %v, want the dictionary confidence 1", c)
+	}
+
+	replace, err := analyzer.NewClassifierPolicy(bayes, analyzer.ClassifierReplace, 0.9)
+	if err != nil {
+		t.Fatalf("NewClassifierPolicy: %v", err)
+	}
+	rejected := &model.Post{Text: "Сотрудники провели турнир", MatchedRule: analyzer.DetectorTitle}
+	if c := replace.Confidence(rejected, 1); c != 0 || rejected.MatchedRule != analyzer.RuleClassifier {
+		t.Errorf("replace confidence = %v, rule %q, want 0 by classifier", c, rejected.MatchedRule)
+	}
+	if _, err := analyzer.NewClassifierPolicy(bayes, "vote", 0.9); err == nil {
+		t.Errorf("unknown mode accepted")
+	}
+}
+
+func TestValidateNGrams(t *testing.T) {
+	for _, c := range []struct {
+		minN, maxN int
+		valid      bool
+	}{
+		{2, 4, true},
+		{3, 3, true},
+		{0, 4, false},
+		{-1, 2, false},
+		{4, 2, false},
+	} {
+		if err := ValidateNGrams(c.minN, c.maxN); (err == nil) != c.valid {
+			t.Errorf("ValidateNGrams(%d, %d) = %v, want valid %v", c.minN, c.maxN, err, c.valid)
+		}
+	}
+
+	// A model trained with lengths that pass is loaded back.
+	path := filepath.Join(t.TempDir(), "model.json")
+	if err := NewNaiveBayes(3, 3).Save(path); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if _, err := Load(path); err != nil {
+		t.Errorf("Load: %v", err)
+	}
+	if err := NewNaiveBayes(4, 2).Save(path); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if _, err := Load(path); err == nil {
+		t.Error("Load accepted a model with invalid n-gram lengths")
+	}
+}
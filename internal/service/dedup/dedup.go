@@ -0,0 +1,159 @@
+package dedup
+
+import (
+	"hash/fnv"
+	"math/bits"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/ScrpTrx-Go/GoTGParse/internal/domain/model"
+)
+
+// shingleSize is the number of words in a shingle. Three-word shingles keep
+// a small edit from changing more than a few of them.
+const shingleSize = 3
+
+// Fingerprint returns the SimHash of the word shingles of text. Case,
+// punctuation, emoji and links do not change it, and near-duplicate texts get
+// fingerprints that differ in few bits. An empty text has fingerprint 0.
+func Fingerprint(text string) uint64 {
+	words := normalize(text)
+	if len(words) == 0 {
+		return 0
+	}
+	var weights [64]int
+	n := max(1, len(words)-shingleSize+1)
+	for i := 0; i < n; i++ {
+		h := fnv.New64a()
+		h.Write([]byte(strings.Join(words[i:min(i+shingleSize, len(words))], " ")))
+		sum := h.Sum64()
+		for bit := 0; bit < 64; bit++ {
+			if sum&(1<<bit) != 0 {
+				weights[bit]++
+			} else {
+				weights[bit]--
+			}
+		}
+	}
+	var fingerprint uint64
+	for bit, weight := range weights {
+		if weight > 0 {
+			fingerprint |= 1 << bit
+		}
+	}
+	return fingerprint
+}
+
+// Distance is the number of bits two fingerprints differ in.
+func Distance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+func normalize(text string) []string {
+	var words []string
+	for _, field := range strings.Fields(strings.ToLower(text)) {
+		if strings.HasPrefix(field, "http://") || strings.HasPrefix(field, "https://") || strings.HasPrefix(field, "t.me/") {
+			continue
+		}
+		word := strings.Map(func(r rune) rune {
+			if unicode.IsLetter(r) || unicode.IsDigit(r) {
+				return r
+			}
+			return -1
+		}, strings.ReplaceAll(field, "ё", "е"))
+		if word != "" {
+			words = append(words, word)
+		}
+	}
+	return words
+}
+
+// Stage fingerprints analyzed posts so that duplicates can be found later
+// among stored posts.
+type Stage struct{}
+
+func NewStage() *Stage {
+	return &Stage{}
+}
+
+func (s *Stage) Process(post *model.Post) {
+	post.Fingerprint = Fingerprint(post.Text)
+}
+
+// Cluster is a group of near-duplicate posts. Canonical is the earliest of
+// them, Duplicates are the later ones in time order.
+type Cluster struct {
+	Canonical  *model.Post
+	Duplicates []*model.Post
+}
+
+// Clusterer groups posts whose fingerprints differ in at most MaxDistance
+// bits and that were published within Window of another post of the group.
+type Clusterer struct {
+	MaxDistance int
+	Window      time.Duration
+}
+
+func NewClusterer(maxDistance int, window time.Duration) *Clusterer {
+	return &Clusterer{
+		MaxDistance: maxDistance,
+		Window:      window,
+	}
+}
+
+// Cluster returns the clusters of posts in the order of their canonical
+// posts; a post without duplicates is a cluster of its own. Posts stored
+// before fingerprints were introduced are fingerprinted on the fly.
+func (c *Clusterer) Cluster(posts []*model.Post) []Cluster {
+	sorted := make([]*model.Post, len(posts))
+	copy(sorted, posts)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Timestamp.Before(sorted[j].Timestamp)
+	})
+	fingerprints := make([]uint64, len(sorted))
+	for i, post := range sorted {
+		fingerprints[i] = post.Fingerprint
+		if fingerprints[i] == 0 {
+			fingerprints[i] = Fingerprint(post.Text)
+		}
+	}
+
+	parent := make([]int, len(sorted))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+	for i := range sorted {
+		for j := i + 1; j < len(sorted) && sorted[j].Timestamp.Sub(sorted[i].Timestamp) <= c.Window; j++ {
+			if fingerprints[i] == 0 || Distance(fingerprints[i], fingerprints[j]) > c.MaxDistance {
+				continue
+			}
+			// The earlier post stays the root, so it becomes canonical.
+			if ri, rj := find(i), find(j); ri != rj {
+				parent[max(ri, rj)] = min(ri, rj)
+			}
+		}
+	}
+
+	var clusters []Cluster
+	index := make(map[int]int)
+	for i, post := range sorted {
+		root := find(i)
+		if root == i {
+			index[i] = len(clusters)
+			clusters = append(clusters, Cluster{Canonical: post})
+			continue
+		}
+		cluster := &clusters[index[root]]
+		cluster.Duplicates = append(cluster.Duplicates, post)
+	}
+	return clusters
+}
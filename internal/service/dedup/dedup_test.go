@@ -0,0 +1,59 @@
+package dedup
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ScrpTrx-Go/GoTGParse/internal/domain/model"
+)
+
+const original = "❗️Председатель СК России поручил доложить о ходе расследования уголовного дела\n" +
+	"Как сообщалось в средствах массовой информации, жители многоквартирного дома в Нижнем Тагиле " +
+	"на протяжении длительного времени жалуются на отсутствие отопления и горячей воды в квартирах. " +
+	"Обращения в управляющую компанию и органы местного самоуправления результатов не принесли. " +
+	"Руководителю ГСУ СК России по Свердловской области поручено возбудить уголовное дело и доложить о результатах. " +
+	"https://t.me/sledcom_press/1"
+
+const edited = "Председатель СК России поручил доложить о ходе расследования уголовного дела.\n" +
+	"Как сообщалось в средствах массовой информации, жители многоквартирного дома в Нижнем Тагиле " +
+	"на протяжении длительного времени жалуются на отсутствие отопления и горячей воды в квартирах. " +
+	"Обращения в управляющую компанию и органы местного самоуправления результатов так и не принесли. " +
+	"Руководителю ГСУ СК России по Свердловской области поручено возбудить уголовное дело и доложить о результатах."
+
+const other = "❗️Председатель СК России поручил доложить о ходе проверки\n" +
+	"В Самаре ученик школы получил травму на уроке физкультуры. По данному факту организована " +
+	"процессуальная проверка, руководителю СУ СК России по Самарской области поручено доложить о результатах."
+
+func TestFingerprint(t *testing.T) {
+	if d := Distance(Fingerprint(original), Fingerprint(edited)); d > 6 {
+		t.Errorf("distance of near-duplicates = %d, want at most 6", d)
+	}
+	if d := Distance(Fingerprint(original), Fingerprint(other)); d <= 6 {
+		t.Errorf("distance of different posts = %d, want more than 6", d)
+	}
+	if Fingerprint("❗️ https://t.me/x") != 0 {
+		t.Errorf("fingerprint of a text without words is not 0")
+	}
+}
+
+func TestCluster(t *testing.T) {
+	start := time.Date(2025, time.July, 21, 10, 0, 0, 0, time.UTC)
+	first := &model.Post{ID: 1, Username: "sledcom_press", Text: original, Timestamp: start}
+	repost := &model.Post{ID: 2, Username: "infocentrskrf", Text: edited, Timestamp: start.Add(2 * time.Hour)}
+	unrelated := &model.Post{ID: 3, Username: "sledcom_press", Text: other, Timestamp: start.Add(time.Hour)}
+	late := &model.Post{ID: 4, Username: "sledcom_press", Text: original, Timestamp: start.Add(10 * 24 * time.Hour)}
+
+	clusters := NewClusterer(6, 72*time.Hour).Cluster([]*model.Post{repost, late, unrelated, first})
+	if len(clusters) != 3 {
+		t.Fatalf("clusters = %d, want 3", len(clusters))
+	}
+	if clusters[0].Canonical != first || len(clusters[0].Duplicates) != 1 || clusters[0].Duplicates[0] != repost {
+		t.Errorf("first cluster = %+v, want post 1 with duplicate 2", clusters[0])
+	}
+	if clusters[1].Canonical != unrelated || len(clusters[1].Duplicates) != 0 {
+		t.Errorf("second cluster = %+v, want post 3 alone", clusters[1])
+	}
+	if clusters[2].Canonical != late {
+		t.Errorf("third cluster = %+v, want post 4 outside the window", clusters[2])
+	}
+}
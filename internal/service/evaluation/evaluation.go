@@ -0,0 +1,286 @@
+package evaluation
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/ScrpTrx-Go/GoTGParse/internal/config"
+	"github.com/ScrpTrx-Go/GoTGParse/internal/domain/contracts"
+	"github.com/ScrpTrx-Go/GoTGParse/internal/domain/model"
+)
+
+// Decisions the analyzer is evaluated on.
+const (
+	DecisionErrand = "errand"
+	DecisionRegion = "region"
+	DecisionType   = "type"
+)
+
+// Sample is a labeled post. Datasets are JSON Lines files with one sample per
+// line.
+type Sample struct {
+	ID      string   `json:"id,omitempty"`
+	Channel string   `json:"channel"`
+	Text    string   `json:"text"`
+	Errand  bool     `json:"errand"`
+	Regions []string `json:"regions,omitempty"`
+	Special bool     `json:"special,omitempty"`
+}
+
+// Metrics counts the decisions of one kind. For regions every expected or
+// predicted region of a sample is a decision of its own.
+type Metrics struct {
+	TruePositive  int     `json:"true_positive"`
+	FalsePositive int     `json:"false_positive"`
+	FalseNegative int     `json:"false_negative"`
+	Precision     float64 `json:"precision"`
+	Recall        float64 `json:"recall"`
+	F1            float64 `json:"f1"`
+}
+
+// Mismatch is a sample on which the analyzer disagrees with the label.
+type Mismatch struct {
+	ID       string `json:"id"`
+	Channel  string `json:"channel"`
+	Decision string `json:"decision"`
+	Expected string `json:"expected"`
+	Got      string `json:"got"`
+	Title    string `json:"title"`
+}
+
+type Report struct {
+	Dictionaries string             `json:"dictionaries"`
+	Samples      int                `json:"samples"`
+	Metrics      map[string]Metrics `json:"metrics"`
+	Mismatches   []Mismatch         `json:"mismatches"`
+}
+
+func LoadDataset(path string) ([]Sample, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadDataset(f)
+}
+
+func ReadDataset(r io.Reader) ([]Sample, error) {
+	var samples []Sample
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		data := strings.TrimSpace(scanner.Text())
+		if data == "" {
+			continue
+		}
+		var sample Sample
+		if err := json.Unmarshal([]byte(data), &sample); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if sample.ID == "" {
+			sample.ID = fmt.Sprint(line)
+		}
+		samples = append(samples, sample)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return samples, nil
+}
+
+func WriteDataset(w io.Writer, samples []Sample) error {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	for _, sample := range samples {
+		if err := enc.Encode(sample); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Evaluate runs the samples through the analyzer and compares its decisions
+// with the labels. A post counts as an errand if its errand confidence reaches
+// the review threshold, and as special if its type confidence reaches the
+// special type threshold.
+func Evaluate(ctx context.Context, analyzer contracts.PostAnalyzer, samples []Sample, confidence config.Confidence) (*Report, error) {
+	in := make(chan *model.Post)
+	out, _ := analyzer.RunAnalyzePipeline(ctx, in)
+	go func() {
+		defer close(in)
+		for i, sample := range samples {
+			post := &model.Post{ID: int64(i), Username: sample.Channel, Text: sample.Text}
+			select {
+			case <-ctx.Done():
+				return
+			case in <- post:
+			}
+		}
+	}()
+
+	results := make(map[int64]*model.Post, len(samples))
+	for post := range out {
+		results[post.ID] = post
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	report := &Report{Samples: len(samples)}
+	var errand, region, special Metrics
+	for i, sample := range samples {
+		post := results[int64(i)]
+		var gotErrand, gotSpecial bool
+		var gotRegions []string
+		if post != nil && post.IsErrand && post.ErrandConfidence >= confidence.Review {
+			gotErrand = true
+			gotRegions = post.Regions
+			gotSpecial = post.TypeConfidence >= confidence.SpecialType
+			report.Dictionaries = post.DictionaryVersion
+		}
+
+		if errand.add(sample.Errand, gotErrand) {
+			report.mismatch(sample, DecisionErrand, fmt.Sprint(sample.Errand), fmt.Sprint(gotErrand))
+		}
+		if !sample.Errand && !gotErrand {
+			continue
+		}
+		if special.add(sample.Special, gotSpecial) {
+			report.mismatch(sample, DecisionType, fmt.Sprint(sample.Special), fmt.Sprint(gotSpecial))
+		}
+		if region.addSets(sample.Regions, gotRegions) {
+			report.mismatch(sample, DecisionRegion, strings.Join(sorted(sample.Regions), ", "), strings.Join(sorted(gotRegions), ", "))
+		}
+	}
+	report.Metrics = map[string]Metrics{
+		DecisionErrand: errand.finish(),
+		DecisionRegion: region.finish(),
+		DecisionType:   special.finish(),
+	}
+	return report, nil
+}
+
+// add counts a binary decision and reports whether it is wrong.
+func (m *Metrics) add(expected, got bool) bool {
+	switch {
+	case expected && got:
+		m.TruePositive++
+	case got:
+		m.FalsePositive++
+	case expected:
+		m.FalseNegative++
+	}
+	return expected != got
+}
+
+// addSets counts the decisions on a set of labels and reports whether the
+// sets differ.
+func (m *Metrics) addSets(expected, got []string) bool {
+	mismatch := false
+	for _, label := range got {
+		if slices.Contains(expected, label) {
+			m.TruePositive++
+		} else {
+			m.FalsePositive++
+			mismatch = true
+		}
+	}
+	for _, label := range expected {
+		if !slices.Contains(got, label) {
+			m.FalseNegative++
+			mismatch = true
+		}
+	}
+	return mismatch
+}
+
+func (m Metrics) finish() Metrics {
+	if m.TruePositive+m.FalsePositive > 0 {
+		m.Precision = float64(m.TruePositive) / float64(m.TruePositive+m.FalsePositive)
+	}
+	if m.TruePositive+m.FalseNegative > 0 {
+		m.Recall = float64(m.TruePositive) / float64(m.TruePositive+m.FalseNegative)
+	}
+	if m.Precision+m.Recall > 0 {
+		m.F1 = 2 * m.Precision * m.Recall / (m.Precision + m.Recall)
+	}
+	return m
+}
+
+func (r *Report) mismatch(sample Sample, decision, expected, got string) {
+	title, _, _ := strings.Cut(sample.Text, "\n")
+	r.Mismatches = append(r.Mismatches, Mismatch{
+		ID:       sample.ID,
+		Channel:  sample.Channel,
+		Decision: decision,
+		Expected: expected,
+		Got:      got,
+		Title:    strings.TrimSpace(title),
+	})
+}
+
+// Regressions compares the F1 scores with a baseline report and describes
+// every decision that got worse by more than tolerance.
+func (r *Report) Regressions(baseline *Report, tolerance float64) []string {
+	var regressions []string
+	for _, decision := range []string{DecisionErrand, DecisionRegion, DecisionType} {
+		before, ok := baseline.Metrics[decision]
+		if !ok {
+			continue
+		}
+		after := r.Metrics[decision]
+		if after.F1 < before.F1-tolerance {
+			regressions = append(regressions, fmt.Sprintf("%s: F1 %.4f < baseline %.4f", decision, after.F1, before.F1))
+		}
+	}
+	return regressions
+}
+
+func (r *Report) WriteText(w io.Writer) {
+	fmt.Fprintf(w, "Samples: %d, dictionaries: %s\n\n", r.Samples, r.Dictionaries)
+	fmt.Fprintf(w, "%-8s %6s %6s %6s %9s %9s %9s\n", "decision", "tp", "fp", "fn", "precision", "recall", "f1")
+	for _, decision := range []string{DecisionErrand, DecisionRegion, DecisionType} {
+		m := r.Metrics[decision]
+		fmt.Fprintf(w, "%-8s %6d %6d %6d %9.4f %9.4f %9.4f\n",
+			decision, m.TruePositive, m.FalsePositive, m.FalseNegative, m.Precision, m.Recall, m.F1)
+	}
+	if len(r.Mismatches) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "\nMismatches: %d\n", len(r.Mismatches))
+	for _, m := range r.Mismatches {
+		fmt.Fprintf(w, "%s\t%s\t%s\texpected %q, got %q\t%s\n", m.ID, m.Channel, m.Decision, m.Expected, m.Got, m.Title)
+	}
+}
+
+func LoadReport(path string) (*Report, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var report Report
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("parse report %s: %w", path, err)
+	}
+	return &report, nil
+}
+
+func (r *Report) Save(path string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+func sorted(values []string) []string {
+	values = slices.Clone(values)
+	slices.Sort(values)
+	return values
+}
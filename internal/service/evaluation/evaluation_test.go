@@ -0,0 +1,57 @@
+package evaluation_test
+
+import (
+	"context"
+	"maps"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ScrpTrx-Go/GoTGParse/internal/config"
+	"github.com/ScrpTrx-Go/GoTGParse/internal/service/analyzer"
+	"github.com/ScrpTrx-Go/GoTGParse/internal/service/evaluation"
+	pkg "github.com/ScrpTrx-Go/GoTGParse/pkg/logger"
+)
+
+func TestEvaluateLabeledDataset(t *testing.T) {
+	samples, err := evaluation.LoadDataset("../../../testdata/labeled.jsonl")
+	if err != nil {
+		t.Fatalf("LoadDataset: %v", err)
+	}
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(configPath, []byte("logger:\n  level: error\n  file_path: "+filepath.Join(dir, "app.log")+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := config.LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	log, err := pkg.NewZapLogger(cfg.Logger)
+	if err != nil {
+		t.Fatalf("NewZapLogger: %v", err)
+	}
+	rules, err := analyzer.NewChannelRules(cfg.Channels)
+	if err != nil {
+		t.Fatalf("NewChannelRules: %v", err)
+	}
+	dict := analyzer.NewDictionariesCreator().CreateDictionaries()
+	workers := analyzer.NewAnalyzeWorkers(2, log, dict, rules, nil, nil, cfg.Confidence.SpecialType)
+
+	report, err := evaluation.Evaluate(context.Background(), analyzer.NewPostPipeline(log, workers, cfg.Analyzer.Output), samples, cfg.Confidence)
+	if err != nil {
+		t.Fatalf("Evaluate: %v", err)
+	}
+	for decision, m := range report.Metrics {
+		if m.F1 != 1 {
+			t.Errorf("%s: F1 = %v, want 1 (mismatches %+v)", decision, m.F1, report.Mismatches)
+		}
+	}
+
+	worse := *report
+	worse.Metrics = maps.Clone(report.Metrics)
+	worse.Metrics[evaluation.DecisionErrand] = evaluation.Metrics{F1: 0.5}
+	if got := worse.Regressions(report, 0.01); len(got) != 1 {
+		t.Errorf("Regressions = %v, want one", got)
+	}
+}
@@ -0,0 +1,230 @@
+package extractor
+
+import (
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/ScrpTrx-Go/GoTGParse/internal/domain/model"
+)
+
+var (
+	articlePattern = regexp.MustCompile(`(?i)(?:(?:п\.|пункт[а-я]*)\s*«?([а-я])»?\s*)?(?:(?:ч\.|част[а-я]*)\s*(\d+(?:\.\d+)?)\s*)?(?:ст\.|стать[а-я]*)\s*(\d+(?:\.\d+)?)\s*(?:УК\s*(?:РФ|России)|Уголовного кодекса)`)
+
+	datePattern     = regexp.MustCompile(`(?i)(?:в срок до|не позднее|до)\s+(\d{1,2})\s+(января|февраля|марта|апреля|мая|июня|июля|августа|сентября|октября|ноября|декабря)(?:\s+(\d{4})(?:\s*г(?:ода|\.)?)?)?`)
+	numericPattern  = regexp.MustCompile(`(?i)(?:в срок до|не позднее|до)\s+(\d{1,2})\.(\d{1,2})\.(\d{4})`)
+	periodPattern   = regexp.MustCompile(`(?i)в (?:течение|срок)(?: \p{L}+)? (?:суток|дней|дня|недели|недель|месяца|месяцев)`)
+	sentencePattern = regexp.MustCompile(`[.!?]\s+\p{Lu}|\n`)
+)
+
+var months = []string{"января", "февраля", "марта", "апреля", "мая", "июня",
+	"июля", "августа", "сентября", "октября", "ноября", "декабря"}
+
+// addresseeHeads start the name of the official or unit an errand is given to.
+var addresseeHeads = []string{"руководителю", "руководителям", "начальнику", "начальникам", "главе", "прокурору"}
+
+// abbreviations end with a dot that ends neither a sentence nor an addressee,
+// as in "по г. Москве".
+var abbreviations = []string{"г", "гг", "ул", "им", "обл", "д", "с", "п", "пос", "пгт", "р-н", "пр", "пер", "просп", "ст", "ч", "т", "тов"}
+
+// notInfinitives end like infinitives but are not verbs.
+var notInfinitives = []string{"пять", "опять", "память", "мать", "гать", "путь", "ртуть", "суть", "муть",
+	"жуть", "зять", "рать", "кровать", "печать", "благодать", "сеть", "треть", "плеть", "клеть", "нить",
+	"прыть", "плоть", "дочь", "ночь", "речь", "печь", "дичь", "желчь", "полночь"}
+
+// Extractor fills ErrandDetails of the errands passing through the analysis
+// pipeline. Posts that are not errands are left as they are.
+type Extractor struct{}
+
+func NewExtractor() *Extractor {
+	return &Extractor{}
+}
+
+func (e *Extractor) Process(post *model.Post) {
+	if !post.IsErrand {
+		return
+	}
+	post.Details = Extract(post.Text, post.Timestamp)
+}
+
+// Extract returns the fields found in text. The addressee, actions and
+// deadline are only taken from the sentences giving the errand, so that e.g.
+// a custody term is not read as a deadline. Relative dates are resolved
+// against published: a date without a year is the first such date not before
+// the publication day in its location.
+func Extract(text string, published time.Time) *model.ErrandDetails {
+	details := &model.ErrandDetails{
+		Articles: articles(text),
+	}
+	for _, sentence := range errandSentences(text) {
+		if details.Deadline == "" {
+			details.Deadline, details.DeadlineDate = deadline(sentence, published)
+		}
+		words := strings.Fields(sentence)
+		if details.Addressee == "" {
+			details.Addressee = addressee(words)
+		}
+		for _, word := range words {
+			word = strings.ToLower(strings.TrimFunc(word, notLetter))
+			if isInfinitive(word) && !slices.Contains(details.Actions, word) {
+				details.Actions = append(details.Actions, word)
+			}
+		}
+	}
+	return details
+}
+
+func articles(text string) []model.Article {
+	var found []model.Article
+	for _, m := range articlePattern.FindAllStringSubmatch(text, -1) {
+		article := model.Article{Point: strings.ToLower(m[1]), Part: m[2], Article: m[3]}
+		if !slices.Contains(found, article) {
+			found = append(found, article)
+		}
+	}
+	return found
+}
+
+// errandSentences returns the sentences that give an errand. Abbreviations
+// such as "ст. 105" do not end a sentence since a digit follows them, nor do
+// "г. Москве" and initials, see isAbbreviation.
+func errandSentences(text string) []string {
+	var sentences []string
+	start := 0
+	for _, loc := range sentencePattern.FindAllStringIndex(text, -1) {
+		end := loc[0] + 1
+		if text[loc[0]] == '.' && isAbbreviation(lastWord(text[start:end])) {
+			continue
+		}
+		sentences = append(sentences, text[start:end])
+		start = end
+	}
+	sentences = append(sentences, text[start:])
+
+	var errands []string
+	for _, sentence := range sentences {
+		lowered := strings.ToLower(sentence)
+		if strings.Contains(lowered, "поруч") || strings.Contains(lowered, "доложить") {
+			errands = append(errands, strings.TrimSpace(sentence))
+		}
+	}
+	return errands
+}
+
+// addressee returns the words from an addressee head up to the first verb or
+// punctuation mark, at most twelve words.
+func addressee(words []string) string {
+	for i, word := range words {
+		if !slices.Contains(addresseeHeads, strings.ToLower(strings.TrimFunc(word, notLetter))) {
+			continue
+		}
+		var phrase []string
+		for _, w := range words[i:min(i+12, len(words))] {
+			bare := strings.ToLower(strings.TrimFunc(w, notLetter))
+			if len(phrase) > 0 && (isInfinitive(bare) || strings.HasPrefix(bare, "поруч")) {
+				break
+			}
+			if isAbbreviation(w) {
+				phrase = append(phrase, w)
+				continue
+			}
+			phrase = append(phrase, strings.TrimRightFunc(w, unicode.IsPunct))
+			if strings.ContainsAny(w, ",.;:") {
+				break
+			}
+		}
+		return strings.Join(phrase, " ")
+	}
+	return ""
+}
+
+// isAbbreviation reports whether word is an abbreviation with a dot, such as
+// "г." or "ул.", or initials such as "А." and "А.И.".
+func isAbbreviation(word string) bool {
+	word = strings.TrimLeftFunc(word, unicode.IsPunct)
+	if !strings.HasSuffix(word, ".") {
+		return false
+	}
+	parts := strings.Split(strings.TrimSuffix(word, "."), ".")
+	if len(parts) == 1 && slices.Contains(abbreviations, strings.ToLower(parts[0])) {
+		return true
+	}
+	for _, part := range parts {
+		if r := []rune(part); len(r) != 1 || !unicode.IsUpper(r[0]) {
+			return false
+		}
+	}
+	return true
+}
+
+// lastWord returns the last whitespace separated word of text.
+func lastWord(text string) string {
+	fields := strings.Fields(text)
+	if len(fields) == 0 {
+		return ""
+	}
+	return fields[len(fields)-1]
+}
+
+// isInfinitive guesses an infinitive by its ending; "-ти" is only taken after
+// the stems of verbs such as "провести" and "найти" since nouns like "области"
+// end with it too.
+func isInfinitive(word string) bool {
+	if slices.Contains(notInfinitives, word) {
+		return false
+	}
+	for _, suffix := range []string{"ться", "тись", "ать", "ять", "еть", "ить", "оть", "уть", "ыть", "ести", "зти", "йти", "чь"} {
+		if strings.HasSuffix(word, suffix) && len([]rune(word)) > len([]rune(suffix))+1 {
+			return true
+		}
+	}
+	return false
+}
+
+func deadline(text string, published time.Time) (string, *time.Time) {
+	if m := numericPattern.FindStringSubmatch(text); m != nil {
+		day, _ := strconv.Atoi(m[1])
+		month, _ := strconv.Atoi(m[2])
+		year, _ := strconv.Atoi(m[3])
+		if date, ok := makeDate(year, month, day, published.Location()); ok {
+			return m[0], &date
+		}
+		return m[0], nil
+	}
+	if m := datePattern.FindStringSubmatch(text); m != nil {
+		day, _ := strconv.Atoi(m[1])
+		month := slices.Index(months, strings.ToLower(m[2])) + 1
+		year := published.Year()
+		if m[3] != "" {
+			year, _ = strconv.Atoi(m[3])
+		}
+		date, ok := makeDate(year, month, day, published.Location())
+		y, mon, d := published.Date()
+		if ok && m[3] == "" && date.Before(time.Date(y, mon, d, 0, 0, 0, 0, published.Location())) {
+			date = date.AddDate(1, 0, 0)
+		}
+		if ok {
+			return m[0], &date
+		}
+		return m[0], nil
+	}
+	if m := periodPattern.FindString(text); m != "" {
+		return m, nil
+	}
+	return "", nil
+}
+
+func makeDate(year, month, day int, loc *time.Location) (time.Time, bool) {
+	date := time.Date(year, time.Month(month), day, 0, 0, 0, 0, loc)
+	if date.Day() != day || int(date.Month()) != month {
+		return time.Time{}, false
+	}
+	return date, true
+}
+
+func notLetter(r rune) bool {
+	return !unicode.IsLetter(r) && r != '-'
+}
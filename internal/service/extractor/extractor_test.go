@@ -0,0 +1,147 @@
+package extractor
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/ScrpTrx-Go/GoTGParse/internal/domain/model"
+)
+
+func TestExtract(t *testing.T) {
+	text := "Председатель СК России поручил доложить о ходе расследования\n" +
+		"В Нижнем Тагиле возбуждено уголовное дело по ч. 1 ст. 238 УК РФ и п. «в» ч. 2 ст. 105 УК РФ. " +
+		"Председатель СК России Александр Бастрыкин поручил руководителю ГСУ СК России по Свердловской области " +
+		"Ивану Иванову возбудить уголовное дело и доложить о результатах до 15 августа."
+	published := time.Date(2025, time.July, 21, 12, 0, 0, 0, time.UTC)
+
+	details := Extract(text, published)
+
+	wantArticles := []model.Article{{Part: "1", Article: "238"}, {Point: "в", Part: "2", Article: "105"}}
+	if !slices.Equal(details.Articles, wantArticles) {
+		t.Errorf("Articles = %v, want %v", details.Articles, wantArticles)
+	}
+	if want := "руководителю ГСУ СК России по Свердловской области Ивану Иванову"; details.Addressee != want {
+		t.Errorf("Addressee = %q, want %q", details.Addressee, want)
+	}
+	if want := []string{"доложить", "возбудить"}; !slices.Equal(details.Actions, want) {
+		t.Errorf("Actions = %v, want %v", details.Actions, want)
+	}
+	if details.Deadline != "до 15 августа" {
+		t.Errorf("Deadline = %q", details.Deadline)
+	}
+	if want := time.Date(2025, time.August, 15, 0, 0, 0, 0, time.UTC); details.DeadlineDate == nil || !details.DeadlineDate.Equal(want) {
+		t.Errorf("DeadlineDate = %v, want %v", details.DeadlineDate, want)
+	}
+}
+
+func TestExtractDeadline(t *testing.T) {
+	published := time.Date(2025, time.December, 20, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		text string
+		want string
+		date *time.Time
+	}{
+		{"доложить до 10 января", "до 10 января", ptr(time.Date(2026, time.January, 10, 0, 0, 0, 0, time.UTC))},
+		{"в срок до 01.02.2026 доложить", "в срок до 01.02.2026", ptr(time.Date(2026, time.February, 1, 0, 0, 0, 0, time.UTC))},
+		{"доложить в течение недели", "в течение недели", nil},
+		{"доложить о результатах", "", nil},
+	}
+	for _, tt := range tests {
+		got, date := deadline(tt.text, published)
+		if got != tt.want {
+			t.Errorf("deadline(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+		if (date == nil) != (tt.date == nil) || date != nil && !date.Equal(*tt.date) {
+			t.Errorf("deadline(%q) date = %v, want %v", tt.text, date, tt.date)
+		}
+	}
+}
+
+func TestExtractIgnoresOtherSentences(t *testing.T) {
+	text := "Обвиняемый заключён под стражу до 15 сентября. " +
+		"Председатель СК России поручил руководителю управления защитить дочь заявительницы и доложить о результатах."
+	details := Extract(text, time.Date(2025, time.July, 21, 12, 0, 0, 0, time.UTC))
+
+	if details.Deadline != "" || details.DeadlineDate != nil {
+		t.Errorf("Deadline = %q (%v), want none", details.Deadline, details.DeadlineDate)
+	}
+	if want := []string{"защитить", "доложить"}; !slices.Equal(details.Actions, want) {
+		t.Errorf("Actions = %v, want %v", details.Actions, want)
+	}
+}
+
+func TestExtractAbbreviations(t *testing.T) {
+	published := time.Date(2025, time.July, 21, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		text      string
+		addressee string
+		actions   []string
+	}{
+		{
+			"Председатель СК России поручил руководителю ГСУ СК России по г. Москве возбудить уголовное дело и доложить о результатах.",
+			"руководителю ГСУ СК России по г. Москве",
+			[]string{"возбудить", "доложить"},
+		},
+		{
+			"Председатель СК России поручил руководителю ГСУ СК России по г. Санкт-Петербургу доложить о ходе проверки.",
+			"руководителю ГСУ СК России по г. Санкт-Петербургу",
+			[]string{"доложить"},
+		},
+		{
+			"А.И. Бастрыкин поручил начальнику больницы им. Пирогова доложить о проверке.",
+			"начальнику больницы им. Пирогова",
+			[]string{"доложить"},
+		},
+	}
+	for _, tt := range tests {
+		details := Extract(tt.text, published)
+		if details.Addressee != tt.addressee {
+			t.Errorf("Addressee = %q, want %q", details.Addressee, tt.addressee)
+		}
+		if !slices.Equal(details.Actions, tt.actions) {
+			t.Errorf("%q: Actions = %v, want %v", tt.text, details.Actions, tt.actions)
+		}
+	}
+
+	sentences := errandSentences("Нападение произошло на ул. Ленина в г. Омске. Председатель СК поручил доложить.")
+	if want := []string{"Председатель СК поручил доложить."}; !slices.Equal(sentences, want) {
+		t.Errorf("errandSentences = %q, want %q", sentences, want)
+	}
+}
+
+func TestIsInfinitiveNouns(t *testing.T) {
+	for _, word := range []string{"дочь", "печь", "сеть", "кровать", "ночь", "области", "путь"} {
+		if isInfinitive(word) {
+			t.Errorf("isInfinitive(%q) = true", word)
+		}
+	}
+	for _, word := range []string{"доложить", "провести", "найти", "помочь", "обеспечить", "разобраться"} {
+		if !isInfinitive(word) {
+			t.Errorf("isInfinitive(%q) = false", word)
+		}
+	}
+}
+
+func TestExtractDeadlineLocalDay(t *testing.T) {
+	moscow := time.FixedZone("MSK", 3*60*60)
+	// The UTC day starts at 03:00 in Moscow, comparing with it would move
+	// the deadline a year ahead.
+	published := time.Date(2025, time.December, 31, 23, 30, 0, 0, moscow)
+	_, date := deadline("доложить до 31 декабря", published)
+	if want := time.Date(2025, time.December, 31, 0, 0, 0, 0, moscow); date == nil || !date.Equal(want) {
+		t.Errorf("date = %v, want %v", date, want)
+	}
+}
+
+func TestProcessSkipsNonErrands(t *testing.T) {
+	post := &model.Post{Text: "ч. 1 ст. 238 УК РФ"}
+	NewExtractor().Process(post)
+	if post.Details != nil {
+		t.Errorf("Details = %+v, want nil", post.Details)
+	}
+}
+
+func ptr(t time.Time) *time.Time {
+	return &t
+}
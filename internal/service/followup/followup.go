@@ -0,0 +1,231 @@
+package followup
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/ScrpTrx-Go/GoTGParse/internal/config"
+	"github.com/ScrpTrx-Go/GoTGParse/internal/domain/contracts"
+	"github.com/ScrpTrx-Go/GoTGParse/internal/domain/model"
+	"github.com/ScrpTrx-Go/GoTGParse/internal/service/review"
+)
+
+// markers are the phrases of a post that reports the outcome of a Chairman
+// errand.
+var markers = []string{
+	"по поручению председател",
+	"во исполнение поручения председател",
+	"по поручению главы ск",
+	"по поручению александра бастрыкина",
+}
+
+// stemLength is the number of leading letters words are compared by, which
+// is enough to ignore Russian endings.
+const stemLength = 6
+
+// stopStems are stems every errand and follow-up shares, so they tell
+// nothing about the incident.
+var stopStems = []string{
+	"предсе", "поруч", "следст", "уголов", "росси", "доложи", "руково", "резуль", "рассле",
+	"возбуж", "бастры", "алекса", "информ", "сообщ", "област", "управл", "провер", "процес",
+	"обстоя", "которы", "данно", "данны", "факту",
+}
+
+// Linker links follow-up posts of the IC channels to the earlier errands of
+// the sledcom channels they report on. A follow-up must name a region of the
+// errand, be published within the window after it and share enough words
+// with it for a score of at least the minimal score.
+type Linker struct {
+	sections map[string]string
+	window   time.Duration
+	minScore float64
+}
+
+func NewLinker(channels []config.ChannelRule, cfg config.FollowUpConfig) *Linker {
+	sections := make(map[string]string, len(channels))
+	for _, channel := range channels {
+		sections[channel.Username] = channel.ReportSection
+	}
+	return &Linker{
+		sections: sections,
+		window:   cfg.Window,
+		minScore: cfg.MinScore,
+	}
+}
+
+// IsFollowUp reports whether the post says it reports on a Chairman errand.
+func IsFollowUp(post *model.Post) bool {
+	text := strings.ToLower(post.Text)
+	for _, marker := range markers {
+		if strings.Contains(text, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+type candidate struct {
+	post  *model.Post
+	stems map[string]struct{}
+}
+
+// Link returns a link for every follow-up among posts that matches one of
+// the errands among them, to the errand with the best score.
+func (l *Linker) Link(posts []*model.Post) []model.FollowUp {
+	var errands []candidate
+	for _, post := range posts {
+		if l.sections[post.Username] == config.SectionSledcom && review.Apply(post) {
+			errands = append(errands, candidate{post: post, stems: stems(post.Text)})
+		}
+	}
+	slices.SortStableFunc(errands, func(a, b candidate) int {
+		return a.post.Timestamp.Compare(b.post.Timestamp)
+	})
+
+	var links []model.FollowUp
+	for _, post := range posts {
+		if l.sections[post.Username] != config.SectionIC || !IsFollowUp(post) {
+			continue
+		}
+		followUp := candidate{post: post, stems: stems(post.Text)}
+		var best *candidate
+		var bestScore float64
+		for i := range errands {
+			errand := &errands[i]
+			delay := post.Timestamp.Sub(errand.post.Timestamp)
+			if delay <= 0 || delay > l.window || !sameRegion(followUp, *errand) {
+				continue
+			}
+			// Errands are in time order, so of equally similar errands the
+			// latest one is taken.
+			if score := overlap(followUp.stems, errand.stems); score >= l.minScore && score >= bestScore {
+				best, bestScore = errand, score
+			}
+		}
+		if best == nil {
+			continue
+		}
+		links = append(links, model.FollowUp{
+			Username:       post.Username,
+			PostID:         post.ID,
+			Link:           post.Link,
+			PublishedAt:    post.Timestamp,
+			ErrandUsername: best.post.Username,
+			ErrandID:       best.post.ID,
+			Score:          bestScore,
+		})
+	}
+	return links
+}
+
+// sameRegion reports whether the follow-up was assigned a region of the
+// errand or mentions it. Follow-ups are often not errands themselves, so the
+// analyzer leaves their regions empty.
+func sameRegion(followUp, errand candidate) bool {
+	for _, region := range errand.post.Regions {
+		if slices.Contains(followUp.post.Regions, region) {
+			return true
+		}
+		for _, word := range words(region) {
+			stem := stemOf(word)
+			if isStopStem(stem) {
+				continue
+			}
+			if _, ok := followUp.stems[stem]; ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// overlap is the share of the stems of the smaller set that the other set
+// has too.
+func overlap(a, b map[string]struct{}) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+	shared := 0
+	for stem := range a {
+		if _, ok := b[stem]; ok {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(a))
+}
+
+func stems(text string) map[string]struct{} {
+	found := make(map[string]struct{})
+	for _, word := range words(text) {
+		if len([]rune(word)) < 4 {
+			continue
+		}
+		if stem := stemOf(word); !isStopStem(stem) {
+			found[stem] = struct{}{}
+		}
+	}
+	return found
+}
+
+func words(text string) []string {
+	return strings.FieldsFunc(strings.ReplaceAll(strings.ToLower(text), "ё", "е"), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+func stemOf(word string) string {
+	runes := []rune(word)
+	return string(runes[:min(stemLength, len(runes))])
+}
+
+func isStopStem(stem string) bool {
+	for _, stop := range stopStems {
+		if strings.HasPrefix(stem, stop) {
+			return true
+		}
+	}
+	return false
+}
+
+// Service links the follow-ups of a period to the errands published up to
+// the linker window before it and stores the links.
+type Service struct {
+	store  contracts.FollowUpStore
+	linker *Linker
+}
+
+func NewService(store contracts.FollowUpStore, linker *Linker) *Service {
+	return &Service{
+		store:  store,
+		linker: linker,
+	}
+}
+
+// Link stores the links of the follow-ups published between from and to and
+// returns how many were found.
+func (s *Service) Link(ctx context.Context, from, to time.Time) (int, error) {
+	posts, err := s.store.GetPostsByPeriod(ctx, from.Add(-s.linker.window), to)
+	if err != nil {
+		return 0, fmt.Errorf("load posts: %w", err)
+	}
+	var links []model.FollowUp
+	for _, link := range s.linker.Link(posts) {
+		if !link.PublishedAt.Before(from) {
+			links = append(links, link)
+		}
+	}
+	if len(links) == 0 {
+		return 0, nil
+	}
+	if err := s.store.SaveFollowUps(ctx, links); err != nil {
+		return 0, err
+	}
+	return len(links), nil
+}
@@ -0,0 +1,58 @@
+package followup
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ScrpTrx-Go/GoTGParse/internal/config"
+	"github.com/ScrpTrx-Go/GoTGParse/internal/domain/model"
+)
+
+func TestLink(t *testing.T) {
+	channels := []config.ChannelRule{
+		{Username: "sledcom_press", ReportSection: config.SectionSledcom},
+		{Username: "infocentrskrf", ReportSection: config.SectionIC},
+	}
+	linker := NewLinker(channels, config.FollowUpConfig{Window: 30 * 24 * time.Hour, MinScore: 0.3})
+	start := time.Date(2025, time.July, 1, 10, 0, 0, 0, time.UTC)
+
+	errand := &model.Post{
+		ID: 1, Username: "sledcom_press", IsErrand: true, Timestamp: start,
+		Regions: []string{"Свердловская область"},
+		Text: "❗️Председатель СК России поручил доложить о нарушении прав жителей аварийного дома\n" +
+			"В Нижнем Тагиле жильцы аварийного дома на улице Ленина остались без отопления и горячей воды. " +
+			"Руководителю ГСУ СК России по Свердловской области поручено доложить о результатах.",
+	}
+	other := &model.Post{
+		ID: 2, Username: "sledcom_press", IsErrand: true, Timestamp: start.Add(time.Hour),
+		Regions: []string{"Самарская область"},
+		Text: "❗️Председатель СК России поручил доложить о травмировании школьника\n" +
+			"В Самаре школьник получил травму на уроке физкультуры. " +
+			"Руководителю СУ СК России по Самарской области поручено доложить о результатах.",
+	}
+	followUp := &model.Post{
+		ID: 10, Username: "infocentrskrf", Timestamp: start.Add(5 * 24 * time.Hour), Link: "https://t.me/infocentrskrf/10",
+		Text: "По поручению Председателя СК России в Свердловской области возбуждено уголовное дело " +
+			"по факту отсутствия отопления и горячей воды в аварийном доме на улице Ленина в Нижнем Тагиле.",
+	}
+	unrelated := &model.Post{
+		ID: 11, Username: "infocentrskrf", Timestamp: start.Add(6 * 24 * time.Hour),
+		Text: "В Свердловской области возбуждено уголовное дело об отсутствии отопления в аварийном доме в Нижнем Тагиле.",
+	}
+	late := &model.Post{
+		ID: 12, Username: "infocentrskrf", Timestamp: start.Add(60 * 24 * time.Hour),
+		Text: followUp.Text,
+	}
+
+	links := linker.Link([]*model.Post{errand, other, followUp, unrelated, late})
+	if len(links) != 1 {
+		t.Fatalf("links = %+v, want one", links)
+	}
+	link := links[0]
+	if link.PostID != 10 || link.ErrandUsername != "sledcom_press" || link.ErrandID != 1 || link.Link != followUp.Link {
+		t.Errorf("link = %+v, want follow-up 10 of errand 1", link)
+	}
+	if link.Score < 0.3 || link.Score > 1 {
+		t.Errorf("score = %v", link.Score)
+	}
+}
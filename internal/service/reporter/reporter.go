@@ -4,29 +4,50 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"maps"
+	"math"
 	"os"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
 	"time"
 
 	"baliance.com/gooxml/document"
 	"baliance.com/gooxml/schema/soo/wml"
+	"github.com/ScrpTrx-Go/GoTGParse/internal/config"
 	"github.com/ScrpTrx-Go/GoTGParse/internal/domain/contracts"
 	"github.com/ScrpTrx-Go/GoTGParse/internal/domain/model"
+	"github.com/ScrpTrx-Go/GoTGParse/internal/service/dedup"
+	"github.com/ScrpTrx-Go/GoTGParse/internal/service/review"
 	pkg "github.com/ScrpTrx-Go/GoTGParse/pkg/logger"
 	"github.com/xuri/excelize/v2"
 )
 
+const (
+	SectionSledcom = config.SectionSledcom
+	SectionIC      = config.SectionIC
+)
+
 type Reporter struct {
-	log pkg.Logger
-	db  contracts.SaverPostgres
+	log        pkg.Logger
+	db         contracts.SaverPostgres
+	sections   map[string]string
+	confidence config.Confidence
+	dedup      config.DedupConfig
 }
 
-func NewReporter(log pkg.Logger, db contracts.SaverPostgres) *Reporter {
+func NewReporter(log pkg.Logger, db contracts.SaverPostgres, channels []config.ChannelRule, confidence config.Confidence, dedup config.DedupConfig) *Reporter {
+	sections := make(map[string]string, len(channels))
+	for _, channel := range channels {
+		sections[channel.Username] = channel.ReportSection
+	}
 	return &Reporter{
-		log: log,
-		db:  db,
+		log:        log,
+		db:         db,
+		sections:   sections,
+		confidence: confidence,
+		dedup:      dedup,
 	}
 }
 
@@ -44,7 +65,7 @@ func (r *Reporter) GenerateFullReport(ctx context.Context, from, to time.Time) e
 		return nil
 	}
 
-	rd := NewReportData(r.log)
+	rd := NewReportData(r.log, r.sections, r.confidence, r.dedup)
 	rd.Process(posts)
 
 	if err := rd.SaveAll(); err != nil {
@@ -57,10 +78,19 @@ func (r *Reporter) GenerateFullReport(ctx context.Context, from, to time.Time) e
 }
 
 type ReportData struct {
-	log    pkg.Logger
-	sled   []*SledcomPress
-	ic     []*RegionCounter
-	errors map[string][]*model.Post
+	log        pkg.Logger
+	sections   map[string]string
+	confidence config.Confidence
+	dedup      config.DedupConfig
+	sled       []*SledcomPress
+	ic         []*RegionCounter
+	errors     map[string][]*model.Post
+	review     map[string][]*model.Post
+	errands    []*model.Post
+	// duplicates maps a reported post to the later near-duplicates of it
+	// that were not counted.
+	duplicates map[*model.Post][]*model.Post
+	dropped    int
 }
 
 type RegionCounter struct {
@@ -74,43 +104,91 @@ type SledcomPress struct {
 	Posts []*model.Post
 }
 
-func NewReportData(log pkg.Logger) *ReportData {
+// NewReportData creates report data; sections maps a channel username to the
+// report section its posts feed, confidence decides between the main report
+// and the review queue, dedup whether near-duplicates are counted once.
+func NewReportData(log pkg.Logger, sections map[string]string, confidence config.Confidence, dedupConfig config.DedupConfig) *ReportData {
 	return &ReportData{
-		log:    log,
-		errors: make(map[string][]*model.Post),
+		log:        log,
+		sections:   sections,
+		confidence: confidence,
+		dedup:      dedupConfig,
+		errors:     make(map[string][]*model.Post),
+		review:     make(map[string][]*model.Post),
+		duplicates: make(map[*model.Post][]*model.Post),
 	}
 }
 
 func (r *ReportData) Process(posts []*model.Post) {
 	r.log.Info("Processing posts", "total", len(posts))
+	var errands []*model.Post
 	for _, post := range posts {
-		if checkError(post) {
+		// A reviewed post is reported as the reviewer decided.
+		if !review.Apply(post) || post.ErrandConfidence < r.confidence.Review {
+			r.dropped++
+			continue
+		}
+		errands = append(errands, post)
+	}
+	if r.dedup.CountClusters {
+		errands = r.canonical(errands)
+	}
+
+	for _, post := range errands {
+		if checkError(post, r.isSpecial(post)) {
 			r.errors[post.ErrorType] = append(r.errors[post.ErrorType], post)
 			continue
 		}
-		switch post.Username {
-		case "sledcom_press":
+		if reason := review.LowConfidence(post, r.confidence); reason != "" {
+			r.review[reason] = append(r.review[reason], post)
+			continue
+		}
+		switch section := r.sections[post.Username]; section {
+		case SectionSledcom:
 			r.addSledcom(post)
-		case "infocentrskrf":
+		case SectionIC:
 			r.addIC(post)
+		default:
+			r.log.Warn("No report section for channel", "username", post.Username, "section", section)
+			continue
+		}
+		r.errands = append(r.errands, post)
+	}
+	r.log.Info("Finished processing posts", "sledcom", len(r.sled), "ic", len(r.ic), "errors", len(r.errors),
+		"review", len(r.review), "dropped", r.dropped, "duplicates", len(posts)-r.dropped-len(errands))
+}
+
+// canonical returns the earliest post of every cluster of near-duplicates
+// and remembers the others.
+func (r *ReportData) canonical(posts []*model.Post) []*model.Post {
+	clusters := dedup.NewClusterer(r.dedup.MaxDistance, r.dedup.Window).Cluster(posts)
+	canonical := make([]*model.Post, 0, len(clusters))
+	for _, cluster := range clusters {
+		canonical = append(canonical, cluster.Canonical)
+		if len(cluster.Duplicates) > 0 {
+			r.duplicates[cluster.Canonical] = cluster.Duplicates
 		}
 	}
-	r.log.Info("Finished processing posts", "sledcom", len(r.sled), "infocentrskrf", len(r.ic), "errors", len(r.errors))
+	return canonical
 }
 
-func checkError(post *model.Post) bool {
+func (r *ReportData) isSpecial(post *model.Post) bool {
+	return post.TypeConfidence >= r.confidence.SpecialType
+}
+
+func checkError(post *model.Post, special bool) bool {
 	if post.ErrorType != "" {
 		return true
 	}
 	if len(post.Regions) == 0 {
-		post.ErrorType = "Not found region!"
+		post.ErrorType = review.ReasonNoRegion
 		return true
 	}
 	if strings.TrimSpace(post.Text) == "" {
 		post.ErrorType = "Empty text!"
 		return true
 	}
-	if len(post.Regions) > 1 && post.ErrandType {
+	if len(post.Regions) > 1 && special {
 		post.ErrorType = "more than 1 region, but errand type is true"
 		return true
 	}
@@ -123,7 +201,7 @@ func (r *ReportData) addSledcom(post *model.Post) {
 		for _, entry := range r.sled {
 			if entry.Info.RegionName == region {
 				entry.Posts = append(entry.Posts, post)
-				if post.ErrandType {
+				if r.isSpecial(post) {
 					entry.Info.SpecErrandCounter++
 				} else {
 					entry.Info.CasualErrandCounter++
@@ -137,7 +215,7 @@ func (r *ReportData) addSledcom(post *model.Post) {
 				Info:  RegionCounter{RegionName: region},
 				Posts: []*model.Post{post},
 			}
-			if post.ErrandType {
+			if r.isSpecial(post) {
 				e.Info.SpecErrandCounter++
 			} else {
 				e.Info.CasualErrandCounter++
@@ -152,7 +230,7 @@ func (r *ReportData) addIC(post *model.Post) {
 		found := false
 		for _, entry := range r.ic {
 			if entry.RegionName == region {
-				if post.ErrandType {
+				if r.isSpecial(post) {
 					entry.SpecErrandCounter++
 				} else {
 					entry.CasualErrandCounter++
@@ -163,7 +241,7 @@ func (r *ReportData) addIC(post *model.Post) {
 		}
 		if !found {
 			e := &RegionCounter{RegionName: region}
-			if post.ErrandType {
+			if r.isSpecial(post) {
 				e.SpecErrandCounter++
 			} else {
 				e.CasualErrandCounter++
@@ -179,10 +257,14 @@ func (r *ReportData) SaveAll() error {
 		r.log.Error("Failed to save sledcom.docx", "err", err)
 		return err
 	}
-	if err := r.saveErrorDoc(); err != nil {
+	if err := savePostsDoc(r.errors, "reports/errors.docx"); err != nil {
 		r.log.Error("Failed to save errors.docx", "err", err)
 		return err
 	}
+	if err := savePostsDoc(r.review, "reports/review.docx"); err != nil {
+		r.log.Error("Failed to save review.docx", "err", err)
+		return err
+	}
 	if err := r.saveExcel(); err != nil {
 		r.log.Error("Failed to save Excel report", "err", err)
 		return err
@@ -200,6 +282,12 @@ func (r *ReportData) saveDocSledcom() error {
 		para.SetStyle("Heading1")
 		run.Properties().SetBold(true)
 		run.AddText(region.Info.RegionName)
+		if summary := localitySummary(region.Info.RegionName, region.Posts); summary != "" {
+			doc.AddParagraph().AddRun().AddText("Населённые пункты: " + summary)
+		}
+		if summary := categorySummary(region.Posts); summary != "" {
+			doc.AddParagraph().AddRun().AddText("Категории: " + summary)
+		}
 
 		sort.Slice(region.Posts, func(i, j int) bool {
 			return region.Posts[i].Timestamp.Before(region.Posts[j].Timestamp)
@@ -207,7 +295,27 @@ func (r *ReportData) saveDocSledcom() error {
 
 		for _, post := range region.Posts {
 			doc.AddParagraph().AddRun().AddText(fmt.Sprintf("Время публикации: %v", post.Timestamp.Format("2006-01-02 15:04:05")))
-			doc.AddParagraph().AddRun().AddText(strings.Join(post.Regions, ", "))
+			place := strings.Join(post.Regions, ", ")
+			for _, locality := range post.Localities {
+				place += ", " + locality.Name
+			}
+			if len(post.SecondaryRegions) > 0 {
+				place += " (также упоминается: " + strings.Join(post.SecondaryRegions, ", ") + ")"
+			}
+			doc.AddParagraph().AddRun().AddText(place)
+			if len(post.Categories) > 0 {
+				doc.AddParagraph().AddRun().AddText("Категории: " + strings.Join(post.Categories, ", "))
+			}
+			if post.FollowUp != nil {
+				doc.AddParagraph().AddRun().AddText(fmt.Sprintf("Публичный ответ через %s: %s",
+					followUpDelay(post), post.FollowUp.Link))
+			}
+			if duplicates := r.duplicates[post]; len(duplicates) > 0 {
+				doc.AddParagraph().AddRun().AddText(fmt.Sprintf("Повторные публикации: %d", len(duplicates)))
+			}
+			for _, line := range detailLines(post.Details) {
+				doc.AddParagraph().AddRun().AddText(line)
+			}
 
 			lines := strings.Split(post.Text, "\n")
 			for idx, line := range lines {
@@ -233,10 +341,57 @@ func (r *ReportData) saveDocSledcom() error {
 	return doc.SaveToFile("reports/sledcom.docx")
 }
 
-func (r *ReportData) saveErrorDoc() error {
+// localitySummary counts the posts of the region by locality, most frequent
+// first, e.g. "Нижний Тагил (2), Екатеринбург (1)".
+func localitySummary(region string, posts []*model.Post) string {
+	counts := make(map[string]int)
+	var names []string
+	for _, post := range posts {
+		for _, locality := range post.Localities {
+			if locality.Region != region {
+				continue
+			}
+			if counts[locality.Name] == 0 {
+				names = append(names, locality.Name)
+			}
+			counts[locality.Name]++
+		}
+	}
+	return countedList(names, counts)
+}
+
+// categorySummary counts the posts by category, most frequent first.
+func categorySummary(posts []*model.Post) string {
+	counts := make(map[string]int)
+	var names []string
+	for _, post := range posts {
+		for _, category := range post.Categories {
+			if counts[category] == 0 {
+				names = append(names, category)
+			}
+			counts[category]++
+		}
+	}
+	return countedList(names, counts)
+}
+
+func countedList(names []string, counts map[string]int) string {
+	sort.SliceStable(names, func(i, j int) bool {
+		return counts[names[i]] > counts[names[j]]
+	})
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		parts = append(parts, fmt.Sprintf("%s (%d)", name, counts[name]))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// savePostsDoc writes posts grouped by the reason they are left out of the
+// main report.
+func savePostsDoc(groups map[string][]*model.Post, path string) error {
 	doc := document.New()
-	for errType, posts := range r.errors {
-		doc.AddParagraph().AddRun().AddText(errType)
+	for reason, posts := range groups {
+		doc.AddParagraph().AddRun().AddText(reason)
 		for _, post := range posts {
 			doc.AddParagraph().AddRun().AddText(fmt.Sprintf("Время публикации: %v", post.Timestamp.Format("2006-01-02 15:04:05")))
 			lines := strings.Split(post.Text, "\n")
@@ -255,10 +410,51 @@ func (r *ReportData) saveErrorDoc() error {
 			run := hl.AddRun()
 			run.Properties().SetStyle("Hyperlink")
 			run.AddText("Открыть пост в Telegram")
+			for _, line := range traceLines(post) {
+				doc.AddParagraph().AddRun().AddText(line)
+			}
 			doc.AddParagraph().AddRun().AddText("----------")
 		}
 	}
-	return doc.SaveToFile("reports/errors.docx")
+	return doc.SaveToFile(path)
+}
+
+// traceLines renders the confidences and the analysis trace of a post.
+func traceLines(post *model.Post) []string {
+	if post.Review != nil {
+		return []string{fmt.Sprintf("Проверено: %s, %s", post.Review.Reviewer, post.Review.Note)}
+	}
+	lines := []string{fmt.Sprintf("Уверенность: поручение %.2f, особый тип %.2f", post.ErrandConfidence, post.TypeConfidence)}
+	for _, region := range post.Regions {
+		if confidence, ok := post.RegionConfidence[region]; ok {
+			lines = append(lines, fmt.Sprintf("Уверенность в регионе %s: %.2f", region, confidence))
+		}
+	}
+	trace := post.Trace
+	if trace == nil {
+		return lines
+	}
+	lines = append(lines, "Трассировка анализа:")
+	if post.MatchedRule != "" {
+		lines = append(lines, fmt.Sprintf("Правило: %s", post.MatchedRule))
+	}
+	lines = append(lines, fmt.Sprintf("Заголовок: %s", trace.Title))
+	if trace.ErrandBodyParagraph < 0 {
+		lines = append(lines, "Абзац поручения не найден, использован весь текст")
+	} else {
+		lines = append(lines, fmt.Sprintf("Абзац поручения №%d: %s", trace.ErrandBodyParagraph, trace.ErrandBody))
+	}
+	for _, m := range trace.Matches {
+		lines = append(lines, fmt.Sprintf("Совпадение %s в %s: %q, позиция %d", m.Dictionary, m.Region, m.Term, m.Offset))
+	}
+	for _, region := range trace.Regions {
+		line := fmt.Sprintf("Регион %s (%s): %s", region.Name, region.Source, region.Status)
+		if region.Reason != "" {
+			line += ", " + region.Reason
+		}
+		lines = append(lines, line)
+	}
+	return lines
 }
 
 func (r *ReportData) saveExcel() error {
@@ -302,9 +498,178 @@ func (r *ReportData) saveExcel() error {
 		}
 	}
 
+	if err := r.fillErrandsSheet(f); err != nil {
+		return err
+	}
+	if err := r.fillCategoriesSheet(f); err != nil {
+		return err
+	}
 	return f.Save()
 }
 
+var errandColumns = []string{"Время публикации", "Канал", "Регион", "Другие регионы", "Населённые пункты", "Категории", "Адресат", "Статьи УК РФ", "Действия", "Срок", "Ссылка", "Повторные публикации", "Публичный ответ", "Дней до ответа"}
+
+// fillErrandsSheet lists the reported errands with their extracted details,
+// one row per post.
+func (r *ReportData) fillErrandsSheet(f *excelize.File) error {
+	const sheet = "Поручения"
+	if _, err := f.NewSheet(sheet); err != nil {
+		return err
+	}
+	if err := f.SetSheetRow(sheet, "A1", &errandColumns); err != nil {
+		return err
+	}
+
+	sort.SliceStable(r.errands, func(i, j int) bool {
+		return r.errands[i].Timestamp.Before(r.errands[j].Timestamp)
+	})
+	for i, post := range r.errands {
+		localities := make([]string, 0, len(post.Localities))
+		for _, locality := range post.Localities {
+			localities = append(localities, locality.Name)
+		}
+		row := []any{
+			post.Timestamp.Format("2006-01-02 15:04:05"),
+			post.Username,
+			strings.Join(post.Regions, ", "),
+			strings.Join(post.SecondaryRegions, ", "),
+			strings.Join(localities, ", "),
+			strings.Join(post.Categories, ", "),
+		}
+		details := post.Details
+		if details == nil {
+			details = &model.ErrandDetails{}
+		}
+		row = append(row, details.Addressee, articleList(details), strings.Join(details.Actions, ", "),
+			deadlineText(details), post.Link, duplicateLinks(r.duplicates[post]))
+		if post.FollowUp != nil {
+			row = append(row, post.FollowUp.Link, math.Round(post.FollowUp.PublishedAt.Sub(post.Timestamp).Hours()/2.4)/10)
+		}
+
+		cell, err := excelize.CoordinatesToCellName(1, i+2)
+		if err != nil {
+			return err
+		}
+		if err := f.SetSheetRow(sheet, cell, &row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fillCategoriesSheet counts the reported errands by region and category. A
+// post counts once in each of its regions and categories; the total row
+// counts every post once per category.
+func (r *ReportData) fillCategoriesSheet(f *excelize.File) error {
+	const sheet = "Категории"
+	counts := make(map[string]map[string]int)
+	totals := make(map[string]int)
+	for _, post := range r.errands {
+		for _, category := range post.Categories {
+			totals[category]++
+			for _, region := range post.Regions {
+				if counts[region] == nil {
+					counts[region] = make(map[string]int)
+				}
+				counts[region][category]++
+			}
+		}
+	}
+	categories := slices.Sorted(maps.Keys(totals))
+	regions := slices.Sorted(maps.Keys(counts))
+
+	if _, err := f.NewSheet(sheet); err != nil {
+		return err
+	}
+	header := append([]any{"Регион"}, toAny(categories)...)
+	if err := f.SetSheetRow(sheet, "A1", &header); err != nil {
+		return err
+	}
+	rows := make([][]any, 0, len(regions)+1)
+	for _, region := range regions {
+		row := []any{region}
+		for _, category := range categories {
+			row = append(row, counts[region][category])
+		}
+		rows = append(rows, row)
+	}
+	total := []any{"Итого"}
+	for _, category := range categories {
+		total = append(total, totals[category])
+	}
+	rows = append(rows, total)
+
+	for i, row := range rows {
+		cell, err := excelize.CoordinatesToCellName(1, i+2)
+		if err != nil {
+			return err
+		}
+		if err := f.SetSheetRow(sheet, cell, &row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func toAny(values []string) []any {
+	out := make([]any, len(values))
+	for i, v := range values {
+		out[i] = v
+	}
+	return out
+}
+
+// detailLines describes the extracted details of an errand for sledcom.docx.
+func detailLines(details *model.ErrandDetails) []string {
+	if details == nil {
+		return nil
+	}
+	var lines []string
+	if details.Addressee != "" {
+		lines = append(lines, "Адресат: "+details.Addressee)
+	}
+	if articles := articleList(details); articles != "" {
+		lines = append(lines, "Статьи: "+articles)
+	}
+	if deadline := deadlineText(details); deadline != "" {
+		lines = append(lines, "Срок: "+deadline)
+	}
+	return lines
+}
+
+// followUpDelay is the time from the errand to its follow-up in days, or in
+// hours if it took less than a day.
+func followUpDelay(post *model.Post) string {
+	delay := post.FollowUp.PublishedAt.Sub(post.Timestamp)
+	if delay < 24*time.Hour {
+		return fmt.Sprintf("%.0f ч.", delay.Hours())
+	}
+	return fmt.Sprintf("%.0f дн.", delay.Hours()/24)
+}
+
+func duplicateLinks(posts []*model.Post) string {
+	links := make([]string, 0, len(posts))
+	for _, post := range posts {
+		links = append(links, post.Link)
+	}
+	return strings.Join(links, "\n")
+}
+
+func articleList(details *model.ErrandDetails) string {
+	articles := make([]string, 0, len(details.Articles))
+	for _, article := range details.Articles {
+		articles = append(articles, article.String())
+	}
+	return strings.Join(articles, "; ")
+}
+
+func deadlineText(details *model.ErrandDetails) string {
+	if details.DeadlineDate != nil {
+		return details.DeadlineDate.Format("2006-01-02")
+	}
+	return details.Deadline
+}
+
 func CopyTemplate(src, dst string) error {
 	srcFile, err := os.Open(src)
 	if err != nil {
@@ -0,0 +1,221 @@
+package review
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"time"
+
+	"github.com/ScrpTrx-Go/GoTGParse/internal/config"
+	"github.com/ScrpTrx-Go/GoTGParse/internal/domain/contracts"
+	"github.com/ScrpTrx-Go/GoTGParse/internal/domain/model"
+	"github.com/ScrpTrx-Go/GoTGParse/internal/service/evaluation"
+)
+
+// Reasons a post needs a review.
+const (
+	ReasonLowErrandConfidence = "Низкая уверенность в поручении"
+	ReasonLowRegionConfidence = "Низкая уверенность в регионе"
+	ReasonNoRegion            = "Not found region!"
+	ReasonBelowReview         = "Уверенность ниже порога проверки"
+	ReasonNearMiss            = "Возможно пропущенное поручение"
+)
+
+// Item is a post waiting for a review.
+type Item struct {
+	Post   *model.Post
+	Reason string
+}
+
+// LowConfidence returns why the analyzer is not confident enough about the
+// post for the main report, or "" if it is.
+func LowConfidence(post *model.Post, confidence config.Confidence) string {
+	if post.ErrandConfidence < confidence.Report {
+		return ReasonLowErrandConfidence
+	}
+	for _, region := range post.Regions {
+		if c, ok := post.RegionConfidence[region]; ok && c < confidence.Region {
+			return ReasonLowRegionConfidence
+		}
+	}
+	return ""
+}
+
+// NearMiss returns why a post left out of the report may still be an errand,
+// or "" if nothing suggests it. That is an errand below the review threshold,
+// or a post that is not an errand but matched some of the detector terms or
+// has a classifier probability of at least the review threshold.
+func NearMiss(post *model.Post, confidence config.Confidence) string {
+	if post.IsErrand {
+		if post.ErrandConfidence < confidence.Review {
+			return ReasonBelowReview
+		}
+		return ""
+	}
+	if post.Trace == nil {
+		return ""
+	}
+	// Regions and categories are only matched for errands, so the matches of
+	// a post that is not one come from the detector.
+	if len(post.Trace.Matches) > 0 {
+		return ReasonNearMiss
+	}
+	if p := post.Trace.Classifier; p != nil && *p >= confidence.Review {
+		return ReasonNearMiss
+	}
+	return ""
+}
+
+// Apply replaces the analyzer results of a reviewed post with the review and
+// reports whether the post is an errand. Posts without a review are left as
+// they are.
+func Apply(post *model.Post) bool {
+	r := post.Review
+	if r == nil {
+		return post.IsErrand
+	}
+	post.IsErrand = r.Errand
+	var localities []model.Locality
+	for _, locality := range post.Localities {
+		if slices.Contains(r.Regions, locality.Region) {
+			localities = append(localities, locality)
+		}
+	}
+	// The other regions are ranked against the analyzer's choice, so they do
+	// not hold once a reviewer changed it.
+	if !slices.Equal(post.Regions, r.Regions) {
+		post.SecondaryRegions = nil
+	}
+	post.Regions = r.Regions
+	post.Localities = localities
+	post.RegionConfidence = nil
+	post.ErrandConfidence = 0
+	if r.Errand {
+		post.ErrandConfidence = 1
+	}
+	post.ErrandType = r.Special
+	post.TypeConfidence = 0
+	if r.Special {
+		post.TypeConfidence = 1
+	}
+	post.ErrorType = ""
+	return r.Errand
+}
+
+type Service struct {
+	store      contracts.ReviewStore
+	confidence config.Confidence
+}
+
+func NewService(store contracts.ReviewStore, confidence config.Confidence) *Service {
+	return &Service{
+		store:      store,
+		confidence: confidence,
+	}
+}
+
+// Queue returns the posts of the period that have no review yet and either an
+// error, no region or a confidence below the report thresholds. Posts below
+// the review threshold are not errands for the report; with missed the near
+// misses among them are queued as well to find false negatives, see NearMiss.
+func (s *Service) Queue(ctx context.Context, from, to time.Time, missed bool) ([]Item, error) {
+	posts, err := s.store.GetPostsByPeriod(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+	var items []Item
+	for _, post := range posts {
+		if post.Review != nil {
+			continue
+		}
+		if !post.IsErrand || post.ErrandConfidence < s.confidence.Review {
+			if reason := NearMiss(post, s.confidence); missed && reason != "" {
+				items = append(items, Item{Post: post, Reason: reason})
+			}
+			continue
+		}
+		reason := post.ErrorType
+		if reason == "" && len(post.Regions) == 0 {
+			reason = ReasonNoRegion
+		}
+		if reason == "" {
+			reason = LowConfidence(post, s.confidence)
+		}
+		if reason != "" {
+			items = append(items, Item{Post: post, Reason: reason})
+		}
+	}
+	return items, nil
+}
+
+// Override is a reviewer's decision; nil fields keep the current value.
+type Override struct {
+	Errand   *bool
+	Regions  []string
+	Special  *bool
+	Reviewer string
+	Note     string
+}
+
+// Review stores a review of the post. The values not overridden are taken from
+// the previous review or, for a post reviewed the first time, from the
+// analyzer, so an empty override confirms the current classification.
+func (s *Service) Review(ctx context.Context, username string, id int64, override Override) (*model.Review, error) {
+	post, err := s.store.GetPost(ctx, username, id)
+	if err != nil {
+		return nil, err
+	}
+	if post == nil {
+		return nil, fmt.Errorf("post %s/%d not found", username, id)
+	}
+
+	r := post.Review
+	if r == nil {
+		r = &model.Review{
+			Username: post.Username,
+			PostID:   post.ID,
+			Errand:   post.IsErrand && post.ErrandConfidence >= s.confidence.Review,
+			Regions:  post.Regions,
+			Special:  post.TypeConfidence >= s.confidence.SpecialType,
+		}
+	}
+	if override.Errand != nil {
+		r.Errand = *override.Errand
+	}
+	if override.Regions != nil {
+		r.Regions = override.Regions
+	}
+	if override.Special != nil {
+		r.Special = *override.Special
+	}
+	r.Reviewer = override.Reviewer
+	r.Note = override.Note
+	r.ReviewedAt = time.Now()
+
+	if err := s.store.SaveReview(ctx, r); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Export writes the reviewed posts as a labeled dataset for the evaluation
+// command and returns the number of samples.
+func (s *Service) Export(ctx context.Context, w io.Writer) (int, error) {
+	posts, err := s.store.GetReviewedPosts(ctx)
+	if err != nil {
+		return 0, err
+	}
+	samples := make([]evaluation.Sample, 0, len(posts))
+	for _, post := range posts {
+		samples = append(samples, evaluation.Sample{
+			ID:      fmt.Sprintf("%s/%d", post.Username, post.ID),
+			Channel: post.Username,
+			Text:    post.Text,
+			Errand:  post.Review.Errand,
+			Regions: post.Review.Regions,
+			Special: post.Review.Special,
+		})
+	}
+	return len(samples), evaluation.WriteDataset(w, samples)
+}
@@ -0,0 +1,144 @@
+package review_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ScrpTrx-Go/GoTGParse/internal/config"
+	"github.com/ScrpTrx-Go/GoTGParse/internal/domain/model"
+	"github.com/ScrpTrx-Go/GoTGParse/internal/service/evaluation"
+	"github.com/ScrpTrx-Go/GoTGParse/internal/service/review"
+)
+
+type memoryStore struct {
+	posts []*model.Post
+}
+
+func (m *memoryStore) SaveReview(_ context.Context, r *model.Review) error {
+	post, _ := m.GetPost(context.Background(), r.Username, r.PostID)
+	copied := *r
+	post.Review = &copied
+	return nil
+}
+
+func (m *memoryStore) GetPost(_ context.Context, username string, id int64) (*model.Post, error) {
+	for _, post := range m.posts {
+		if post.Username == username && post.ID == id {
+			return post, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *memoryStore) GetPostsByPeriod(context.Context, time.Time, time.Time) ([]*model.Post, error) {
+	return m.posts, nil
+}
+
+func (m *memoryStore) GetReviewedPosts(context.Context) ([]*model.Post, error) {
+	var posts []*model.Post
+	for _, post := range m.posts {
+		if post.Review != nil {
+			posts = append(posts, post)
+		}
+	}
+	return posts, nil
+}
+
+func TestReviewWorkflow(t *testing.T) {
+	confidence := config.Confidence{Report: 0.75, Review: 0.5, Region: 0.5, SpecialType: 0.5}
+	store := &memoryStore{posts: []*model.Post{
+		{ID: 1, Username: "sledcom_press", IsErrand: true, Text: "sure", ErrandConfidence: 1, Regions: []string{"Омская область"}},
+		{ID: 2, Username: "sledcom_press", IsErrand: true, Text: "maybe", ErrandConfidence: 0.6, Regions: []string{"Омская область"}, TypeConfidence: 0.75},
+		{ID: 3, Username: "sledcom_press", IsErrand: true, Text: "no region", ErrandConfidence: 1},
+	}}
+	service := review.NewService(store, confidence)
+	ctx := context.Background()
+
+	queue, err := service.Queue(ctx, time.Time{}, time.Time{}, false)
+	if err != nil || len(queue) != 2 {
+		t.Fatalf("Queue = %v, %v; want posts 2 and 3", queue, err)
+	}
+
+	if _, err := service.Review(ctx, "sledcom_press", 2, review.Override{Reviewer: "ivanov"}); err != nil {
+		t.Fatalf("confirm: %v", err)
+	}
+	notErrand := false
+	if _, err := service.Review(ctx, "sledcom_press", 3, review.Override{Errand: &notErrand}); err != nil {
+		t.Fatalf("override: %v", err)
+	}
+	if queue, _ := service.Queue(ctx, time.Time{}, time.Time{}, false); len(queue) != 0 {
+		t.Errorf("Queue after review = %v, want empty", queue)
+	}
+
+	confirmed := store.posts[1]
+	if !review.Apply(confirmed) || confirmed.ErrandConfidence != 1 || confirmed.TypeConfidence != 1 {
+		t.Errorf("confirmed post = %+v", confirmed)
+	}
+	if review.Apply(store.posts[2]) {
+		t.Error("overridden post is still an errand")
+	}
+
+	var out strings.Builder
+	if n, err := service.Export(ctx, &out); err != nil || n != 2 {
+		t.Fatalf("Export = %d, %v", n, err)
+	}
+	samples, err := evaluation.ReadDataset(strings.NewReader(out.String()))
+	if err != nil || len(samples) != 2 || !samples[0].Special || samples[1].Errand {
+		t.Errorf("exported samples = %+v, %v", samples, err)
+	}
+}
+
+func TestApplySecondaryRegions(t *testing.T) {
+	confirmed := &model.Post{
+		IsErrand:         true,
+		Regions:          []string{"Омская область"},
+		SecondaryRegions: []string{"Тверская область"},
+		Review:           &model.Review{Errand: true, Regions: []string{"Омская область"}},
+	}
+	review.Apply(confirmed)
+	if len(confirmed.SecondaryRegions) != 1 {
+		t.Errorf("confirmed regions: secondary = %v, want kept", confirmed.SecondaryRegions)
+	}
+
+	promoted := &model.Post{
+		IsErrand:         true,
+		Regions:          []string{"Омская область"},
+		SecondaryRegions: []string{"Тверская область"},
+		Review:           &model.Review{Errand: true, Regions: []string{"Тверская область"}},
+	}
+	review.Apply(promoted)
+	if len(promoted.SecondaryRegions) != 0 {
+		t.Errorf("overridden regions: secondary = %v, want none", promoted.SecondaryRegions)
+	}
+}
+
+func TestQueueMissed(t *testing.T) {
+	confidence := config.Confidence{Report: 0.75, Review: 0.5, Region: 0.5, SpecialType: 0.5}
+	probability := 0.7
+	store := &memoryStore{posts: []*model.Post{
+		{ID: 1, Username: "sledcom_press", IsErrand: true, ErrandConfidence: 0.25, Regions: []string{"Омская область"}},
+		{ID: 2, Username: "sledcom_press", Trace: &model.AnalysisTrace{Matches: []model.TraceMatch{{Dictionary: "verbs", Region: "title", Term: "поруч*"}}}},
+		{ID: 3, Username: "sledcom_press", Trace: &model.AnalysisTrace{Classifier: &probability}},
+		{ID: 4, Username: "sledcom_press", Trace: &model.AnalysisTrace{}},
+	}}
+	service := review.NewService(store, confidence)
+
+	if queue, _ := service.Queue(context.Background(), time.Time{}, time.Time{}, false); len(queue) != 0 {
+		t.Errorf("Queue without missed = %v, want empty", queue)
+	}
+	queue, err := service.Queue(context.Background(), time.Time{}, time.Time{}, true)
+	if err != nil {
+		t.Fatalf("Queue: %v", err)
+	}
+	want := map[int64]string{1: review.ReasonBelowReview, 2: review.ReasonNearMiss, 3: review.ReasonNearMiss}
+	if len(queue) != len(want) {
+		t.Fatalf("Queue = %v, want posts 1, 2 and 3", queue)
+	}
+	for _, item := range queue {
+		if item.Reason != want[item.Post.ID] {
+			t.Errorf("post %d reason = %q, want %q", item.Post.ID, item.Reason, want[item.Post.ID])
+		}
+	}
+}